@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+)
+
+// importCookies reads the x.com and twitter.com cookies of browser exports,
+// signs in with each of them and appends the new accounts to the additional
+// cookies, or makes the first one the main cookie
+func importCookies(args []string) error {
+	flags := newFlagSet(
+		"config import-cookies",
+		"[flags] <file>...",
+		"Import accounts from Netscape cookies.txt files or JSON cookie exports of browser extensions.\nEach account is signed in once, invalid cookies and accounts already configured are skipped.",
+	)
+	asMain := flags.Bool("main", false, "replace the main cookie with the first imported account, the others are added as additional cookies")
+	skipCheck := flags.Bool("skip-check", false, "do not sign in, only skip cookies whose auth_token is already configured")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	candidates := make([]*config.Cookie, 0)
+	for _, path := range flags.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		cookies, err := config.ParseCookieExport(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if len(cookies) == 0 {
+			fmt.Printf("%s: no x.com or twitter.com account found\n", path)
+		}
+		candidates = append(candidates, cookies...)
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	files, err := loadCookieFiles()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancelOnSignal(cancel)()
+
+	// the accounts sign in behind the proxies of the config, like the syncs do
+	var sysCfgHelper SysCfgHelper
+	if !*skipCheck {
+		params := sysCliParams
+		params.NoValidate = true
+		helper := syscfghelper.New(params)
+		defer helper.Close()
+		sysCfgHelper = helper
+	}
+
+	// the accounts already configured, by auth_token and by screen name
+	known := newKnownAccounts()
+	if !*asMain && files.conf.Cookie.AuthToken != "" {
+		known.add(ctx, sysCfgHelper, &files.conf.Cookie, "the main cookie")
+	}
+	for i, cookie := range files.additional {
+		known.add(ctx, sysCfgHelper, cookie, fmt.Sprintf("additional cookie #%d", i+1))
+	}
+
+	accepted := make([]*config.Cookie, 0, len(candidates))
+	for i, cookie := range candidates {
+		label := fmt.Sprintf("cookie #%d (auth_token %s)", i+1, maskSecret(cookie.AuthToken))
+		if dup, ok := known.byToken[cookie.AuthToken]; ok {
+			fmt.Printf("%s: skipped, already %s\n", label, dup)
+			continue
+		}
+
+		screenName := ""
+		if sysCfgHelper != nil {
+			screenName, err = signIn(ctx, sysCfgHelper, cookie)
+			if err != nil {
+				fmt.Printf("%s: skipped, failed to sign in: %v\n", label, err)
+				continue
+			}
+			if dup, ok := known.byScreenName[strings.ToLower(screenName)]; ok {
+				fmt.Printf("%s: skipped, @%s is already %s\n", label, screenName, dup)
+				continue
+			}
+			label = "@" + screenName
+		}
+
+		known.put(cookie, screenName, "imported as "+label)
+		accepted = append(accepted, cookie)
+		fmt.Printf("%s: imported\n", label)
+	}
+
+	if len(accepted) == 0 {
+		fmt.Println("nothing imported")
+		return nil
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	if *asMain {
+		files.conf.Cookie = *accepted[0]
+		accepted = accepted[1:]
+		if err := files.saveConfig(); err != nil {
+			return err
+		}
+		fmt.Println("main cookie saved to", files.confPath)
+	}
+	if len(accepted) > 0 {
+		files.additional = append(files.additional, accepted...)
+		if err := files.saveAdditional(); err != nil {
+			return err
+		}
+		fmt.Printf("%d additional cookies saved to %s\n", len(accepted), files.cookiesPath)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type knownAccounts struct {
+	byToken      map[string]string
+	byScreenName map[string]string
+}
+
+func newKnownAccounts() *knownAccounts {
+	return &knownAccounts{
+		byToken:      make(map[string]string),
+		byScreenName: make(map[string]string),
+	}
+}
+
+// add records a configured account, signing in to learn its screen name unless
+// sysCfgHelper is nil. Accounts that fail to sign in are only known by
+// auth_token.
+func (k *knownAccounts) add(ctx context.Context, sysCfgHelper SysCfgHelper, cookie *config.Cookie, label string) {
+	screenName := ""
+	if sysCfgHelper != nil {
+		name, err := signIn(ctx, sysCfgHelper, cookie)
+		if err != nil {
+			fmt.Printf("%s: failed to sign in: %v\n", label, err)
+		} else {
+			screenName = name
+		}
+	}
+	k.put(cookie, screenName, label)
+}
+
+func (k *knownAccounts) put(cookie *config.Cookie, screenName string, label string) {
+	k.byToken[cookie.AuthToken] = label
+	if screenName != "" {
+		k.byScreenName[strings.ToLower(screenName)] = label
+	}
+}
+
+// signIn returns the screen name of the account of a cookie, through a client
+// of the config, with its proxies and hosts
+func signIn(ctx context.Context, sysCfgHelper SysCfgHelper, cookie *config.Cookie) (string, error) {
+	client, err := sysCfgHelper.NewClient(ctx, cookie)
+	if err != nil {
+		return "", err
+	}
+	return client.GetScreenName(ctx)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+)
+
+// cookieFiles are the config and the additional cookies of the selected
+// profile as stored, with their cookies decrypted in memory
+type cookieFiles struct {
+	stateDir    string
+	confPath    string
+	cookiesPath string
+	confExists  bool
+
+	conf       *config.Config
+	additional []*config.Cookie
+	// cipher is set when a cookie was encrypted, saving then encrypts them all
+	cipher *config.Cipher
+}
+
+func loadCookieFiles() (*cookieFiles, error) {
+	if err := syscfghelper.CheckProfile(sysCliParams); err != nil {
+		return nil, err
+	}
+
+	f := &cookieFiles{conf: &config.Config{}}
+	f.stateDir, f.confPath = syscfghelper.ResolvePaths(sysCliParams)
+	f.cookiesPath = filepath.Join(f.stateDir, syscfghelper.ADDITIONAL_COOKIES_FILE)
+
+	if _, err := os.Stat(f.confPath); err == nil {
+		if f.conf, err = config.ParseConfigFromFile(f.confPath); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		f.confExists = true
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	additional, err := config.ReadAdditionalCookies(f.cookiesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load additional cookies: %w", err)
+	}
+	f.additional = additional
+
+	if !f.conf.Cookie.IsEncrypted() && !config.CookiesEncrypted(f.additional) {
+		return f, nil
+	}
+	if f.cipher, err = syscfghelper.NewCipher(false); err != nil {
+		return nil, err
+	}
+	if err := f.cipher.DecryptCookie(&f.conf.Cookie); err != nil {
+		return nil, err
+	}
+	for _, cookie := range f.additional {
+		if err := f.cipher.DecryptCookie(cookie); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *cookieFiles) saveConfig() error {
+	conf := *f.conf
+	if f.cipher != nil {
+		if err := f.cipher.EncryptCookie(&conf.Cookie); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(f.confPath), 0755); err != nil {
+		return err
+	}
+	f.confExists = true
+	return config.WriteConfig(f.confPath, &conf)
+}
+
+func (f *cookieFiles) saveAdditional() error {
+	cookies := make([]*config.Cookie, 0, len(f.additional))
+	for _, cookie := range f.additional {
+		cookie := *cookie
+		if f.cipher != nil {
+			if err := f.cipher.EncryptCookie(&cookie); err != nil {
+				return err
+			}
+		}
+		cookies = append(cookies, &cookie)
+	}
+	if err := os.MkdirAll(f.stateDir, 0755); err != nil {
+		return err
+	}
+	return config.WriteAdditionalCookies(f.cookiesPath, cookies)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// encryptCookies encrypts or decrypts every cookie of the config and the
+// additional cookies in place
+func encryptCookies(encrypt bool) error {
+	f, err := loadCookieFiles()
+	if err != nil {
+		return err
+	}
+
+	if encrypt && f.cipher == nil {
+		if f.cipher, err = syscfghelper.NewCipher(true); err != nil {
+			return err
+		}
+	}
+	if !encrypt {
+		if f.cipher == nil {
+			fmt.Println("the cookies are not encrypted")
+			return nil
+		}
+		f.cipher = nil
+	}
+
+	if f.confExists {
+		if err := f.saveConfig(); err != nil {
+			return err
+		}
+		fmt.Println("saved", f.confPath)
+	}
+	if len(f.additional) > 0 {
+		if err := f.saveAdditional(); err != nil {
+			return err
+		}
+		fmt.Println("saved", f.cookiesPath)
+	}
+	if encrypt {
+		fmt.Printf("set %s or enter the passphrase when asked to use the cookies\n", syscfghelper.PASSPHRASE_ENV)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/arghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/subscriptionrepo"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DAEMON_DEFAULT_INTERVAL = 24 * time.Hour
+	// the daemon never sleeps longer than this, so new subscriptions are
+	// picked up without a restart
+	DAEMON_MAX_IDLE = time.Minute
+)
+
+// runDaemon keeps re-syncing the enabled subscriptions, each one once its
+// interval since the last successful sync has elapsed
+func runDaemon(args []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	flags := newFlagSet(
+		"daemon",
+		"[flags]",
+		"Keep running and re-sync each enabled subscription once its interval has elapsed.\nStops gracefully on SIGINT, SIGTERM or SIGQUIT, SIGHUP reloads the additional cookies.",
+	)
+
+	addConfigFlags(flags)
+
+	var autoFollow bool
+	var defaultInterval time.Duration
+	flags.BoolVar(&autoFollow, "auto-follow", false, "send follow request automatically to protected users")
+	flags.DurationVar(&defaultInterval, "interval", DAEMON_DEFAULT_INTERVAL, "re-sync interval of subscriptions added without --every")
+
+	flags.Parse(args)
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runDaemon")
+	logger.Infoln("xSync daemon started")
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	stopSignal := cancelOnSignal(cancel)
+	defer stopSignal()
+
+	defer persistRateLimits(sysCfgHelper)()
+	manager, mainClient := newClientManager(ctx, sysCfgHelper)
+	defer reportApiCounts(manager)
+	defer watchAdditionalCookies(ctx, sysCfgHelper, manager)()
+
+	syncHelper := newSyncHelper(sysCfgHelper, db, manager, autoFollow)
+	defer func() {
+		if err := syncHelper.Dump(); err != nil {
+			logger.Errorln("failed to dump failed tweets:", err)
+			return
+		}
+		logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", syncHelper.FailedCount())
+	}()
+
+	// tweets left over by the previous process
+	if err := syncHelper.Retry(ctx); err != nil {
+		logger.Errorln("failed to retry previous tweets:", err)
+	}
+
+	d := &daemon{
+		db:              db,
+		manager:         manager,
+		mainClient:      mainClient,
+		syncHelper:      syncHelper,
+		subRepo:         subscriptionrepo.New(),
+		defaultInterval: defaultInterval,
+	}
+	d.run(ctx)
+
+	logger.Infoln("xSync daemon stopped")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type daemon struct {
+	db         *sqlx.DB
+	manager    *twitterclient.Manager
+	mainClient *twitterclient.Client
+	syncHelper SyncHelper
+	subRepo    SubscriptionRepo
+
+	defaultInterval time.Duration
+}
+
+func (d *daemon) run(ctx context.Context) {
+	logger := log.WithField("function", "daemon.run")
+
+	for ctx.Err() == nil {
+		wait := DAEMON_MAX_IDLE
+
+		subs, err := d.subRepo.ListEnabled(ctx, d.db)
+		if err != nil {
+			logger.Errorln("failed to list subscriptions:", err)
+		} else if err := d.syncDue(ctx, subs); err != nil {
+			// due subscriptions stay due, back off instead of spinning
+			logger.Errorln("failed to sync subscriptions:", err)
+		} else {
+			now := time.Now()
+			for _, sub := range subs {
+				wait = min(wait, sub.NextSyncAt(d.defaultInterval).Sub(now))
+			}
+			wait = max(wait, time.Second)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (d *daemon) syncDue(ctx context.Context, subs []*model.Subscription) error {
+	logger := log.WithField("function", "daemon.syncDue")
+
+	now := time.Now()
+	due := make([]*model.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if sub.IsDue(now, d.defaultInterval) {
+			due = append(due, sub)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	// blocks while every account is rate limited, nil means none is usable
+	if d.manager.SelectClientForMediaRequest(ctx) == nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("no client is available, postponing %d subscriptions", len(due))
+	}
+
+	argHelper := arghelper.New(d.mainClient, nil, nil, nil, nil)
+	if err := argHelper.AddSubscriptions(due); err != nil {
+		return err
+	}
+	logger.Infof("syncing %d due subscriptions", len(due))
+
+	if err := d.syncHelper.Sync(ctx, argHelper.GetTitledUserLists(ctx)); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		// interrupted, let the next start pick them up again
+		return nil
+	}
+
+	for _, sub := range due {
+		if err := d.subRepo.MarkSynced(ctx, d.db, sub.Id, now); err != nil {
+			logger.Errorln("failed to mark subscription synced:", err)
+		}
+	}
+
+	if err := d.syncHelper.Retry(ctx); err != nil {
+		return err
+	}
+	return d.syncHelper.Dump()
+}
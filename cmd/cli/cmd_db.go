@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+const dbUsage = `usage:
+  xSync db info     print the path, size, schema version and row counts
+  xSync db path     print the path of the database file
+  xSync db vacuum   rebuild the database file to reclaim unused space`
+
+var dbTables = []string{
+	"users",
+	"user_previous_names",
+	"user_snapshots",
+	"user_entities",
+	"user_links",
+	"lsts",
+	"lst_entities",
+	"tweets",
+	"medias",
+	"subscriptions",
+}
+
+func runDb(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, dbUsage)
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "info", "path", "vacuum":
+	case "-h", "-help", "--help", "help":
+		fmt.Println(dbUsage)
+		return
+	default:
+		fmt.Fprintln(os.Stderr, dbUsage)
+		os.Exit(2)
+	}
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runDb")
+
+	dbPath, err := sysCfgHelper.GetSqliteDBPath()
+	if err != nil {
+		logger.Fatalln("failed to get database path:", err)
+	}
+	if args[0] == "path" {
+		fmt.Println(dbPath)
+		return
+	}
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	switch args[0] {
+	case "info":
+		err = printDbInfo(db, dbPath)
+	case "vacuum":
+		_, err = db.Exec("VACUUM")
+	}
+	if err != nil {
+		logger.Fatalln(err)
+	}
+}
+
+func printDbInfo(db *sqlx.DB, dbPath string) error {
+	version, err := model.GetSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("path:          ", dbPath)
+	if info, err := os.Stat(dbPath); err == nil {
+		fmt.Printf("size:           %.2f MiB\n", float64(info.Size())/1024/1024)
+	}
+	fmt.Printf("schema version: %d/%d\n", version, model.SchemaVersion)
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS")
+	for _, table := range dbTables {
+		var count int
+		if err := db.Get(&count, fmt.Sprintf("SELECT COUNT(*) FROM %s", table)); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%d\n", table, count)
+	}
+	return w.Flush()
+}
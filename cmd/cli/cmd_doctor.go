@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/database"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DOCTOR_PASS = "PASS"
+	DOCTOR_WARN = "WARN"
+	DOCTOR_FAIL = "FAIL"
+
+	// DOCTOR_MIN_HEADROOM is the share of an endpoint quota below which the
+	// account is about to be rate limited
+	DOCTOR_MIN_HEADROOM = 0.1
+)
+
+type doctorResult struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+type doctor struct {
+	results []*doctorResult
+}
+
+func (d *doctor) add(status string, check string, format string, args ...any) {
+	d.results = append(d.results, &doctorResult{
+		Check:  check,
+		Status: status,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *doctor) count(status string) int {
+	n := 0
+	for _, result := range d.results {
+		if result.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// runDoctor checks the accounts, the storage and the database, and exits 1
+// when a check fails so it can run from cron or a monitoring probe
+func runDoctor(args []string) {
+	flags := newFlagSet(
+		"doctor",
+		"[flags]",
+		"Check every configured account, the storage path, free disk space, database integrity and schema version.\nExits with 1 when a check fails, or also on warnings with --strict.",
+	)
+	asJson := flags.Bool("json", false, "print the checks as json")
+	strict := flags.Bool("strict", false, "exit with 1 on warnings too")
+	offline := flags.Bool("offline", false, "skip the account checks, which sign in to x.com")
+	minFreeGb := flags.Float64("min-free", 1, "fail when less than this many GB are free on the storage path")
+	timeout := flags.Duration("timeout", time.Minute, "time limit of the account checks")
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	params := sysCliParams
+	params.NoValidate = true
+	sysCfgHelper := syscfghelper.New(params)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runDoctor")
+
+	d := &doctor{}
+	conf := sysCfgHelper.GetConfig()
+	if err := conf.Validate(); err != nil {
+		d.add(DOCTOR_FAIL, "config", "%s", strings.ReplaceAll(err.Error(), "\n", "; "))
+	} else {
+		d.add(DOCTOR_PASS, "config", "%s", sysCfgHelper.GetConfigPath())
+	}
+
+	if conf.RootPath != "" {
+		d.checkStorage(conf.RootPath, uint64(*minFreeGb*(1<<30)))
+		d.checkDatabase(filepath.Join(conf.RootPath, syscfghelper.SQLITE_DB_FILE))
+	}
+
+	if !*offline {
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancelOnSignal(cancel)()
+		d.checkAccounts(ctx, &conf.Cookie, sysCfgHelper)
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	if *asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d.results); err != nil {
+			logger.Fatalln(err)
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+		for _, result := range d.results {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, result.Status, result.Detail)
+		}
+		w.Flush()
+		fmt.Printf(
+			"\n%d passed, %d warnings, %d failed\n",
+			d.count(DOCTOR_PASS),
+			d.count(DOCTOR_WARN),
+			d.count(DOCTOR_FAIL),
+		)
+	}
+
+	if d.count(DOCTOR_FAIL) > 0 || (*strict && d.count(DOCTOR_WARN) > 0) {
+		sysCfgHelper.Close()
+		os.Exit(1)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (d *doctor) checkStorage(rootPath string, minFree uint64) {
+	// a missing root path is created on the first sync, check its parent
+	dir := rootPath
+	for {
+		info, err := os.Stat(dir)
+		if err == nil && info.IsDir() {
+			break
+		}
+		if err == nil {
+			d.add(DOCTOR_FAIL, "storage", "%s is not a directory", dir)
+			return
+		}
+		if !errors.Is(err, os.ErrNotExist) || filepath.Dir(dir) == dir {
+			d.add(DOCTOR_FAIL, "storage", "%v", err)
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	file, err := os.CreateTemp(dir, ".xsync-doctor-*")
+	if err != nil {
+		d.add(DOCTOR_FAIL, "storage", "%s is not writable: %v", dir, err)
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+	if dir == rootPath {
+		d.add(DOCTOR_PASS, "storage", "%s is writable", rootPath)
+	} else {
+		d.add(DOCTOR_WARN, "storage", "%s does not exist yet, it will be created in %s", rootPath, dir)
+	}
+
+	free, err := utils.GetFreeSpace(dir)
+	switch {
+	case err != nil:
+		d.add(DOCTOR_WARN, "free space", "failed to get free space: %v", err)
+	case free < minFree:
+		d.add(DOCTOR_FAIL, "free space", "%s free, below %s", formatBytes(free), formatBytes(minFree))
+	default:
+		d.add(DOCTOR_PASS, "free space", "%s free", formatBytes(free))
+	}
+}
+
+func (d *doctor) checkDatabase(dbPath string) {
+	if _, err := os.Stat(dbPath); errors.Is(err, os.ErrNotExist) {
+		d.add(DOCTOR_WARN, "database", "%s does not exist yet, it will be created on the first sync", dbPath)
+		return
+	}
+
+	db, err := database.ConnectDatabaseReadOnly(dbPath)
+	if err != nil {
+		d.add(DOCTOR_FAIL, "database", "failed to open %s: %v", dbPath, err)
+		return
+	}
+	defer db.Close()
+
+	var problems []string
+	if err := db.Select(&problems, "PRAGMA integrity_check"); err != nil {
+		d.add(DOCTOR_FAIL, "database", "integrity check failed: %v", err)
+	} else if len(problems) == 0 {
+		d.add(DOCTOR_FAIL, "database", "integrity check returned nothing")
+	} else if len(problems) != 1 || problems[0] != "ok" {
+		d.add(DOCTOR_FAIL, "database", "integrity check found %d problems, first: %s", len(problems), problems[0])
+	} else {
+		d.add(DOCTOR_PASS, "database", "%s passed the integrity check", dbPath)
+	}
+
+	version, err := model.GetSchemaVersion(db)
+	switch {
+	case err != nil:
+		d.add(DOCTOR_FAIL, "schema", "failed to get the schema version: %v", err)
+	case version > model.SchemaVersion:
+		d.add(DOCTOR_FAIL, "schema", "version %d was written by a newer xSync, this one knows up to %d", version, model.SchemaVersion)
+	case version < model.SchemaVersion:
+		d.add(DOCTOR_WARN, "schema", "version %d, migrated to %d on the next run", version, model.SchemaVersion)
+	default:
+		d.add(DOCTOR_PASS, "schema", "version %d", version)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// checkAccounts signs in with every cookie. The main account is required,
+// the additional ones only fail as warnings since syncing works without them.
+func (d *doctor) checkAccounts(ctx context.Context, main *config.Cookie, sysCfgHelper SysCfgHelper) {
+	seen := make(map[string]string)
+	if main.AuthToken != "" && main.Ct0 != "" {
+		d.checkAccount(ctx, "main account", main, DOCTOR_FAIL, seen, sysCfgHelper)
+	}
+
+	cookies, err := sysCfgHelper.GetAdditionalCookies()
+	if err != nil {
+		d.add(DOCTOR_WARN, "additional accounts", "failed to load: %v", err)
+		return
+	}
+	for i, cookie := range cookies {
+		d.checkAccount(ctx, fmt.Sprintf("additional account #%d", i+1), cookie, DOCTOR_WARN, seen, sysCfgHelper)
+	}
+}
+
+func (d *doctor) checkAccount(ctx context.Context, check string, cookie *config.Cookie, failure string, seen map[string]string, sysCfgHelper SysCfgHelper) {
+	client, err := sysCfgHelper.NewClient(ctx, cookie)
+	if err != nil {
+		d.add(failure, check, "%v", err)
+		return
+	}
+	twitterclient.SetTwitterClientLogger(client, io.Discard)
+
+	screenName, err := client.CheckAccount(ctx)
+	switch {
+	case errors.Is(err, twitterclient.ErrLockedAccount):
+		d.add(failure, check, "@%s is locked, unlock it on x.com", screenName)
+		return
+	case errors.Is(err, twitterclient.ErrSuspendedAccount):
+		d.add(failure, check, "@%s is suspended", screenName)
+		return
+	case errors.Is(err, twitterclient.ErrSignedOut):
+		d.add(failure, check, "the cookie is expired or signed out, export a new one")
+		return
+	case err != nil:
+		d.add(failure, check, "%v", err)
+		return
+	}
+
+	if other, ok := seen[strings.ToLower(screenName)]; ok {
+		d.add(DOCTOR_WARN, check, "@%s is the same account as the %s", screenName, other)
+	} else {
+		seen[strings.ToLower(screenName)] = check
+		d.add(DOCTOR_PASS, check, "signed in as @%s", screenName)
+	}
+
+	now := time.Now()
+	for _, limit := range client.RateLimits() {
+		status := DOCTOR_PASS
+		if limit.Headroom(now) < DOCTOR_MIN_HEADROOM {
+			status = DOCTOR_WARN
+		}
+		d.add(
+			status,
+			fmt.Sprintf("rate limit @%s %s", screenName, path.Base(limit.Path)),
+			"%d/%d left, resets at %s",
+			limit.Remaining,
+			limit.Limit,
+			limit.ResetTime.Local().Format(time.TimeOnly),
+		)
+	}
+}
+
+func formatBytes(n uint64) string {
+	return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/subscriptionrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/tweetrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/userentityrepo"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+var exportColumns = map[string][]string{
+	"users":         {"user_id", "screen_name", "name", "parent_dir", "last_sync", "latest_tweet", "media_count", "tweet_count", "stored_media_count"},
+	"tweets":        {"user_id", "tweet_id", "tweet_time", "content"},
+	"subscriptions": {"id", "kind", "target", "enabled", "interval_seconds", "last_synced_at", "include", "exclude"},
+}
+
+// runExport writes the records of a table as json or csv
+func runExport(args []string) {
+	flags := newFlagSet(
+		"export",
+		"[flags] <users|tweets|subscriptions>",
+		"Export the synced users, the recorded tweets or the subscriptions.",
+	)
+	format := flags.String("format", "json", "output format, json or csv")
+	out := flags.String("out", "", "write to this file instead of stdout")
+	userId := flags.Uint64("user", 0, "only export the tweets of the user specified by user_id")
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	logger := log.WithField("function", "runExport")
+
+	kind := flags.Arg(0)
+	columns, ok := exportColumns[kind]
+	if flags.NArg() != 1 || !ok {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != "json" && *format != "csv" {
+		logger.Fatalln("unknown format:", *format)
+	}
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	records, err := listExportRecords(context.Background(), db, kind, *userId)
+	if err != nil {
+		logger.Fatalf("failed to list %s: %v", kind, err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			logger.Fatalln("failed to create output file:", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "csv" {
+		err = writeCsv(w, columns, records)
+	} else {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(records)
+	}
+	if err != nil {
+		logger.Fatalln("failed to export:", err)
+	}
+}
+
+func listExportRecords(ctx context.Context, db *sqlx.DB, kind string, userId uint64) ([]map[string]any, error) {
+	records := make([]map[string]any, 0)
+
+	switch kind {
+	case "users":
+		statuses, err := userentityrepo.New().ListStatus(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range statuses {
+			records = append(records, userStatusRecord(status))
+		}
+
+	case "tweets":
+		var tweets []*model.Tweet
+		var err error
+		if userId != 0 {
+			tweets, err = tweetrepo.New().GetByUserId(ctx, db, userId)
+		} else {
+			tweets, err = tweetrepo.New().ListAll(ctx, db)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, tweet := range tweets {
+			records = append(records, map[string]any{
+				"user_id":    tweet.UserId,
+				"tweet_id":   tweet.TweetId,
+				"tweet_time": tweet.TweetTime,
+				"content":    tweet.Content,
+			})
+		}
+
+	case "subscriptions":
+		subs, err := subscriptionrepo.New().ListAll(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subs {
+			records = append(records, map[string]any{
+				"id":               sub.Id,
+				"kind":             sub.Kind,
+				"target":           sub.Target,
+				"enabled":          sub.Enabled,
+				"interval_seconds": sub.IntervalSeconds,
+				"last_synced_at":   nullTime(sub.LastSyncedAt.Time, sub.LastSyncedAt.Valid),
+				"include":          sub.Include,
+				"exclude":          sub.Exclude,
+			})
+		}
+	}
+
+	return records, nil
+}
+
+func userStatusRecord(status *model.UserEntityStatus) map[string]any {
+	return map[string]any{
+		"user_id":            status.Uid,
+		"screen_name":        status.ScreenName,
+		"name":               status.Name,
+		"parent_dir":         status.ParentDir,
+		"last_sync":          status.UpdatedAt,
+		"latest_tweet":       nullTime(status.LatestReleaseTime.Time, status.LatestReleaseTime.Valid),
+		"media_count":        status.MediaCount.Int32,
+		"tweet_count":        status.TweetCount,
+		"stored_media_count": status.StoredMediaCount,
+	}
+}
+
+func nullTime(t time.Time, valid bool) any {
+	if !valid {
+		return nil
+	}
+	return t
+}
+
+func writeCsv(w io.Writer, columns []string, records []map[string]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for _, record := range records {
+		for i, column := range columns {
+			switch v := record[column].(type) {
+			case nil:
+				row[i] = ""
+			case time.Time:
+				row[i] = v.Format(time.RFC3339)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
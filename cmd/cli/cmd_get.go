@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/arghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	log "github.com/sirupsen/logrus"
+)
+
+// runGet downloads single tweets, and their threads on demand, into the
+// folders of their authors
+func runGet(args []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	flags := newFlagSet(
+		"get",
+		"[flags] <tweet-url-or-id>...",
+		"Download the given tweets into the folders of their authors, which are saved like \"xSync sync\" does.\nTweets downloaded before are skipped, and the later syncs of their authors skip the tweets downloaded here.",
+	)
+	var thread bool
+	flags.BoolVar(&thread, "thread", false, "also download the tweets of the same author above and below each tweet in its conversation")
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	logger := log.WithField("function", "runGet")
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return
+	}
+	ids := make([]uint64, 0, flags.NArg())
+	for _, arg := range flags.Args() {
+		id, err := arghelper.ParseTweetId(arg)
+		if err != nil {
+			logger.Fatalln(err)
+		}
+		ids = append(ids, id)
+	}
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	stopSignal := cancelOnSignal(cancel)
+	defer stopSignal()
+
+	defer persistRateLimits(sysCfgHelper)()
+	manager, mainClient := newClientManager(ctx, sysCfgHelper)
+	defer reportApiCounts(manager)
+
+	////////////////////////////////////////////////////////////////////////////
+
+	var tweets []*twitterclient.Tweet
+	for _, id := range ids {
+		detail, err := mainClient.GetTweetDetail(ctx, id, thread)
+		if err != nil {
+			logger.Errorf("failed to get tweet %d: %v", id, err)
+			continue
+		}
+		tweets = append(tweets, detail...)
+	}
+	if len(tweets) == 0 {
+		logger.Warnln("no tweet found, exiting")
+		return
+	}
+
+	syncHelper := newSyncHelper(sysCfgHelper, db, manager, false)
+	if err := syncHelper.Get(ctx, tweets); err != nil {
+		logger.Fatalln(err)
+	}
+	if err := syncHelper.Dump(); err != nil {
+		logger.Fatalln("failed to dump failed tweets:", err)
+	}
+	logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", syncHelper.FailedCount())
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	log "github.com/sirupsen/logrus"
+)
+
+const profileUsage = `usage:
+  xSync profile ls                       list the profiles, * marks the selected one
+  xSync profile create [flags] <name>    create a profile, see "xSync profile create -h"
+  xSync profile rm <name>                remove the config, cookies and logs of a profile
+
+select a profile with "xSync --profile <name> <command>" or XSYNC_PROFILE`
+
+// runProfile manages the named profiles, each with its own config, cookies,
+// logs and root path, so its own database and error backup
+func runProfile(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, profileUsage)
+		os.Exit(2)
+	}
+
+	logger := log.WithField("function", "runProfile")
+
+	var err error
+	switch args[0] {
+	case "ls":
+		err = profileList()
+	case "create":
+		err = profileCreate(args[1:])
+	case "rm":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, profileUsage)
+			os.Exit(2)
+		}
+		err = profileRemove(args[1])
+	case "-h", "-help", "--help", "help":
+		fmt.Println(profileUsage)
+	default:
+		fmt.Fprintln(os.Stderr, profileUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Fatalln(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func profileList() error {
+	names, err := syscfghelper.ListProfiles(sysCliParams)
+	if err != nil {
+		return err
+	}
+	selected := syscfghelper.GetProfileName(sysCliParams)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tROOT PATH\tCONFIG")
+	for _, name := range names {
+		mark := ""
+		if name == selected {
+			mark = "*"
+		}
+		confPath := filepath.Join(syscfghelper.GetProfileStateDir(sysCliParams, name), syscfghelper.SYS_CONF_FILE)
+		rootPath := "-"
+		if conf, err := config.ParseConfigFromFile(confPath); err == nil && conf.RootPath != "" {
+			rootPath = conf.RootPath
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, name, rootPath, confPath)
+	}
+	return w.Flush()
+}
+
+func profileCreate(args []string) error {
+	flags := newFlagSet(
+		"profile create",
+		"[flags] <name>",
+		"Create a profile with its own config, cookies and logs.\nGive it a root path of its own so it keeps a separate database and archive.",
+	)
+	conf := &config.Config{}
+	for _, field := range config.Fields {
+		flags.Func(field.Flag, field.Usage, func(value string) error {
+			return conf.Set(field.Key, value)
+		})
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	name := flags.Arg(0)
+	if err := syscfghelper.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == syscfghelper.DEFAULT_PROFILE {
+		return fmt.Errorf("the %s profile always exists", name)
+	}
+
+	stateDir := syscfghelper.GetProfileStateDir(sysCliParams, name)
+	if _, err := os.Stat(stateDir); err == nil {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	// sharing a root path would share the database and the archive
+	if err := syscfghelper.CheckRootPathFree(sysCliParams, name, conf.RootPath); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return err
+	}
+	confPath := filepath.Join(stateDir, syscfghelper.SYS_CONF_FILE)
+	if err := config.WriteConfig(confPath, conf); err != nil {
+		return err
+	}
+
+	fmt.Printf("profile %q created at %s\n", name, stateDir)
+	if err := conf.Validate(); err != nil {
+		fmt.Println()
+		fmt.Printf("finish it with \"xSync --profile %s config set <key> <value>\":\n%v\n", name, err)
+	}
+	return nil
+}
+
+func profileRemove(name string) error {
+	if err := syscfghelper.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == syscfghelper.DEFAULT_PROFILE {
+		return fmt.Errorf("the %s profile can not be removed", name)
+	}
+
+	stateDir := syscfghelper.GetProfileStateDir(sysCliParams, name)
+	if _, err := os.Stat(stateDir); err != nil {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	rootPath := ""
+	if conf, err := config.ParseConfigFromFile(filepath.Join(stateDir, syscfghelper.SYS_CONF_FILE)); err == nil {
+		rootPath = conf.RootPath
+	}
+	if err := os.RemoveAll(stateDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("profile %q removed\n", name)
+	if rootPath != "" {
+		fmt.Printf("its database and archive in %s are kept\n", rootPath)
+	}
+	return nil
+}
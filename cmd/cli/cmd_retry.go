@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	log "github.com/sirupsen/logrus"
+)
+
+// runRetry only drains the tweets dumped by previous runs, nothing new is fetched
+func runRetry(args []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	flags := newFlagSet(
+		"retry",
+		"[flags]",
+		"Download the tweets that failed in previous runs again, without fetching new tweets.\nTweets failing again are kept for the next retry.",
+	)
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runRetry")
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	stopSignal := cancelOnSignal(cancel)
+	defer stopSignal()
+
+	defer persistRateLimits(sysCfgHelper)()
+	manager, _ := newClientManager(ctx, sysCfgHelper)
+	defer reportApiCounts(manager)
+	defer watchAdditionalCookies(ctx, sysCfgHelper, manager)()
+
+	syncHelper := newSyncHelper(sysCfgHelper, db, manager, false)
+	if syncHelper.FailedCount() == 0 {
+		logger.Infoln("no failed tweets to retry")
+		return
+	}
+
+	logger.Infof("retrying %d failed tweets", syncHelper.FailedCount())
+	if err := syncHelper.Retry(ctx); err != nil {
+		logger.Fatalln(err)
+	}
+	if err := syncHelper.Dump(); err != nil {
+		logger.Fatalln("failed to dump failed tweets:", err)
+	}
+	logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", syncHelper.FailedCount())
+}
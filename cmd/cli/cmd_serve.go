@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/serverpkg/server"
+	log "github.com/sirupsen/logrus"
+)
+
+// runServe starts the dashboard on the database of the configured root path
+func runServe(args []string) {
+	flags := newFlagSet(
+		"serve",
+		"[flags]",
+		"Start the web dashboard on the configured database.\nRun it from the repository root, the templates are read from ./cmd/server/templates.",
+	)
+	defaultPort := os.Getenv("PORT")
+	if defaultPort == "" {
+		defaultPort = "8080"
+	}
+	port := flags.String("port", defaultPort, "port to listen on, defaults to $PORT or 8080")
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runServe")
+
+	dbPath, err := sysCfgHelper.GetSqliteDBPath()
+	if err != nil {
+		logger.Fatalln("failed to get database path:", err)
+	}
+
+	srv, err := server.NewServer(dbPath, *port)
+	if err != nil {
+		logger.Fatalln("failed to create server:", err)
+	}
+	defer srv.Close()
+
+	logger.Infof("open http://localhost:%s to view the dashboard", *port)
+	if err := srv.Start(); err != nil {
+		logger.Fatalln("server failed to start:", err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/subscriptionrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/userentityrepo"
+	"github.com/WangWilly/xSync/pkgs/downloading"
+	log "github.com/sirupsen/logrus"
+)
+
+// runStatus prints what has been synced so far, read from user_entities
+func runStatus(args []string) {
+	flags := newFlagSet(
+		"status",
+		"[flags]",
+		"Show the last sync, the latest tweet and the number of tweets and media of every synced user,\nthe most recently synced first.",
+	)
+	asJson := flags.Bool("json", false, "print the users as json")
+	limit := flags.Int("limit", 0, "only show this many users, 0 shows all")
+	budget := flags.Bool("budget", false, "show the rate limit left and the requests made on every endpoint of every account instead")
+	addConfigFlags(flags)
+	flags.Parse(args)
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runStatus")
+
+	if *budget {
+		// the budget of the main account needs its token, which the passphrase
+		// decrypts
+		mainAccount := ""
+		if cookie, err := sysCfgHelper.GetMainCookie(); err != nil {
+			logger.Warnln("failed to decrypt the main cookie:", err)
+		} else {
+			mainAccount = twitterclient.AccountKey(cookie.AuthToken)
+		}
+		if err := printRateLimitBudget(sysCfgHelper.GetRateLimitsPath(), *asJson, sysCfgHelper.GetBudgets(), mainAccount); err != nil {
+			logger.Fatalln("failed to load rate limits:", err)
+		}
+		return
+	}
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	ctx := context.Background()
+	statuses, err := userentityrepo.New().ListStatus(ctx, db)
+	if err != nil {
+		logger.Fatalln("failed to list user entities:", err)
+	}
+	if *limit > 0 && len(statuses) > *limit {
+		statuses = statuses[:*limit]
+	}
+
+	if *asJson {
+		records := make([]map[string]any, 0, len(statuses))
+		for _, status := range statuses {
+			records = append(records, userStatusRecord(status))
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			logger.Fatalln(err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tSCREEN NAME\tLAST SYNC\tLATEST TWEET\tMEDIA\tTWEETS\tFILES")
+	for _, status := range statuses {
+		latest := "-"
+		if status.LatestReleaseTime.Valid {
+			latest = status.LatestReleaseTime.Time.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%d\t%d\t%d\n",
+			status.Uid,
+			status.ScreenName,
+			status.UpdatedAt.Local().Format(time.DateTime),
+			latest,
+			status.MediaCount.Int32,
+			status.TweetCount,
+			status.StoredMediaCount,
+		)
+	}
+	w.Flush()
+
+	////////////////////////////////////////////////////////////////////////////
+
+	subs, err := subscriptionrepo.New().ListAll(ctx, db)
+	if err != nil {
+		logger.Fatalln("failed to list subscriptions:", err)
+	}
+	enabled := 0
+	for _, sub := range subs {
+		if sub.Enabled {
+			enabled++
+		}
+	}
+
+	pending := 0
+	if dumpPath, err := sysCfgHelper.GetErrorBkJsonPath(); err == nil {
+		dumper := downloading.NewDumper(db)
+		if err := dumper.Load(dumpPath); err == nil {
+			pending = dumper.Count()
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d users, %d/%d subscriptions enabled, %d failed tweets waiting for \"xSync retry\"\n", len(statuses), enabled, len(subs), pending)
+}
+
+// printRateLimitBudget prints the quotas saved by the last runs, a quota past
+// its reset time is fully available again, then the requests made this hour
+// and today against the budgets of the accounts
+func printRateLimitBudget(rateLimitsPath string, asJson bool, budgets *twitterclient.Budgets, mainAccount string) error {
+	store, err := twitterclient.NewFileRateLimitStore(rateLimitsPath)
+	if err != nil {
+		return err
+	}
+	accounts := store.Accounts()
+
+	if asJson {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(accounts)
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tENDPOINT\tLEFT\tRESETS\tUPDATED")
+	for _, account := range accounts {
+		name := "@" + account.ScreenName
+		if account.ScreenName == "" {
+			name = account.Account
+		}
+		for _, limit := range account.Limits {
+			left := fmt.Sprintf("%d/%d", limit.Remaining, limit.Limit)
+			resets := limit.ResetTime.Local().Format(time.DateTime)
+			if now.After(limit.ResetTime) {
+				left = fmt.Sprintf("%d/%d", limit.Limit, limit.Limit)
+				resets = "-"
+			}
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\n",
+				name,
+				path.Base(limit.Path),
+				left,
+				resets,
+				account.UpdatedAt.Local().Format(time.DateTime),
+			)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT	ENDPOINT	THIS HOUR	TODAY")
+	for _, account := range accounts {
+		name := "@" + account.ScreenName
+		if account.ScreenName == "" {
+			name = account.Account
+		}
+		budget := budgets.BudgetOf(account.ScreenName, account.Account == mainAccount)
+		for _, usage := range account.Usage {
+			usage = usage.At(now)
+			limits := budget.LimitsOf(usage.Path)
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\n",
+				name,
+				path.Base(usage.Path),
+				formatBudgetUsage(usage.HourCount, limits.PerHour),
+				formatBudgetUsage(usage.DayCount, limits.PerDay),
+			)
+		}
+	}
+	return w.Flush()
+}
+
+func formatBudgetUsage(count int, cap int) string {
+	if cap == 0 {
+		return strconv.Itoa(count)
+	}
+	return fmt.Sprintf("%d/%d", count, cap)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/subscriptionrepo"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+const subUsage = `usage:
+  xSync sub add [--include a,b] [--exclude c,d] [--every 30m|@hourly|@daily|@weekly] [--disabled] <user|user-name|list|foll> <target>...
+  xSync sub rm <id>...
+  xSync sub enable <id>...
+  xSync sub disable <id>...
+  xSync sub ls`
+
+// runSub manages the subscriptions that a bare "xSync sync" downloads
+func runSub(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, subUsage)
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		fmt.Println(subUsage)
+		return
+	}
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	logger := log.WithField("function", "runSub")
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+
+	var err error
+	ctx := context.Background()
+	switch args[0] {
+	case "add":
+		err = subAdd(ctx, db, args[1:])
+	case "rm":
+		err = subForEachId(args[1:], func(id int64) error {
+			return subscriptionrepo.New().Delete(ctx, db, id)
+		})
+	case "enable":
+		err = subForEachId(args[1:], func(id int64) error {
+			return subscriptionrepo.New().SetEnabled(ctx, db, id, true)
+		})
+	case "disable":
+		err = subForEachId(args[1:], func(id int64) error {
+			return subscriptionrepo.New().SetEnabled(ctx, db, id, false)
+		})
+	case "ls":
+		err = subList(ctx, db)
+	default:
+		fmt.Fprintln(os.Stderr, subUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Fatalln(err)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func subAdd(ctx context.Context, db *sqlx.DB, args []string) error {
+	flags := newFlagSet(
+		"sub add",
+		"[flags] <user|user-name|list|foll> <target>...",
+		"Subscribe to users by user_id or screen_name, lists by list_id or followings by user_id.\nAdding an existing subscription replaces its options.",
+	)
+	include := flags.String("include", "", "only download these members of a list or following, by user_id or screen_name")
+	exclude := flags.String("exclude", "", "skip these members of a list or following, by user_id or screen_name")
+	every := flags.String("every", "", "re-sync interval used by the daemon, a duration such as 30m or one of @hourly, @daily, @weekly")
+	disabled := flags.Bool("disabled", false, "add the subscription without syncing it")
+	flags.Parse(args)
+
+	interval, err := parseSubscriptionInterval(*every)
+	if err != nil {
+		return err
+	}
+
+	if flags.NArg() < 2 {
+		return fmt.Errorf("%s", subUsage)
+	}
+
+	kind := flags.Arg(0)
+	isUser := kind == model.SUBSCRIPTION_KIND_USER || kind == model.SUBSCRIPTION_KIND_USER_NAME
+	if isUser && (*include != "" || *exclude != "") {
+		return fmt.Errorf("--include and --exclude only apply to list and foll subscriptions")
+	}
+	var targets []string
+	for _, arg := range flags.Args()[1:] {
+		targets = append(targets, strings.Split(arg, ",")...)
+	}
+
+	repo := subscriptionrepo.New()
+	for _, target := range targets {
+		target, err := normalizeSubscriptionTarget(kind, target)
+		if err != nil {
+			return err
+		}
+		sub := &model.Subscription{
+			Kind:    kind,
+			Target:  target,
+			Include: *include,
+			Exclude: *exclude,
+			Enabled: !*disabled,
+
+			IntervalSeconds: int64(interval / time.Second),
+		}
+		if err := repo.Upsert(ctx, db, sub); err != nil {
+			return fmt.Errorf("failed to save subscription %s %s: %w", kind, target, err)
+		}
+		fmt.Printf("subscription %d: %s %s\n", sub.Id, sub.Kind, sub.Target)
+	}
+	return nil
+}
+
+func normalizeSubscriptionTarget(kind string, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	switch kind {
+	case model.SUBSCRIPTION_KIND_USER_NAME:
+		target = strings.TrimPrefix(target, "@")
+		if target == "" {
+			return "", fmt.Errorf("invalid user screen name: %q", target)
+		}
+		return target, nil
+	case model.SUBSCRIPTION_KIND_USER, model.SUBSCRIPTION_KIND_LIST, model.SUBSCRIPTION_KIND_FOLL:
+		if _, err := strconv.ParseUint(target, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid %s ID: %s", kind, target)
+		}
+		return target, nil
+	default:
+		return "", fmt.Errorf("unknown subscription kind: %s", kind)
+	}
+}
+
+// parseSubscriptionInterval returns 0, the daemon default, for an empty string
+func parseSubscriptionInterval(str string) (time.Duration, error) {
+	switch str {
+	case "":
+		return 0, nil
+	case "@hourly":
+		return time.Hour, nil
+	case "@daily":
+		return 24 * time.Hour, nil
+	case "@weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+
+	interval, err := time.ParseDuration(str)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval: %s", str)
+	}
+	if interval < time.Minute {
+		return 0, fmt.Errorf("interval must be at least 1m: %s", str)
+	}
+	return interval, nil
+}
+
+func subForEachId(args []string, fn func(id int64) error) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", subUsage)
+	}
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid subscription ID: %s", arg)
+		}
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func subList(ctx context.Context, db *sqlx.DB) error {
+	subs, err := subscriptionrepo.New().ListAll(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tKIND\tTARGET\tENABLED\tEVERY\tLAST SYNCED\tINCLUDE\tEXCLUDE")
+	for _, sub := range subs {
+		every := "default"
+		if sub.IntervalSeconds > 0 {
+			every = (time.Duration(sub.IntervalSeconds) * time.Second).String()
+		}
+		lastSynced := "never"
+		if sub.LastSyncedAt.Valid {
+			lastSynced = sub.LastSyncedAt.Time.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%s\t%s\t%s\t%s\n", sub.Id, sub.Kind, sub.Target, sub.Enabled, every, lastSynced, sub.Include, sub.Exclude)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/arghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/subscriptionrepo"
+	log "github.com/sirupsen/logrus"
+)
+
+// runSync downloads the targets given by flags and the enabled subscriptions.
+// legacy is set for the flags of old versions given without a command, which
+// only download the given targets and still accept --conf.
+func runSync(name string, args []string, legacy bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	flags := newFlagSet(
+		name,
+		"[flags]",
+		"Download new tweets of every enabled subscription and of the targets given by flags.\nFailed tweets are retried once before exiting, the rest are kept for \"xSync retry\".",
+	)
+
+	var userTwitterIdsArg arghelper.UserTwitterIdsArg
+	var userTwitterScreenNamesArg arghelper.UserTwitterScreenNamesArg
+	var twitterListIdsArg arghelper.TwitterListIdsArg
+	var userTwitterIdsForFollowersArg arghelper.UserTwitterIdsArg
+	var followerMembersArg arghelper.UserTwitterIdsArg
+	var searchQueriesArg arghelper.SearchQueriesArg
+	var communityIdsArg arghelper.CommunityIdsArg
+	flags.Var(&userTwitterIdsArg, "user", "download tweets from the user specified by user_id since the last download")
+	flags.Var(&userTwitterScreenNamesArg, "user-name", "download tweets from the user specified by screen_name since the last download")
+	flags.Var(&twitterListIdsArg, "list", "batch download each member from list specified by list_id")
+	flags.Var(&userTwitterIdsForFollowersArg, "foll", "batch download each member followed by the user specified by user_id")
+	flags.Var(&followerMembersArg, "followers", "batch download each follower of the user specified by user_id")
+	flags.Var(&searchQueriesArg, "search", "download tweets found by the search query since the last download, into the folders of their authors linked from a folder named after the query")
+	flags.Var(&communityIdsArg, "community", "download tweets posted in the community specified by community_id since the last download, and the recorded spaces shared there")
+
+	addConfigFlags(flags)
+	if legacy {
+		flags.BoolVar(&sysCliParams.ConfOverWrite, "conf", false, "reconfigure, same as \"xSync config wizard\"")
+	}
+
+	var myLists bool
+	var fromSubscriptions bool
+	var autoFollow bool
+	var noRetry bool
+	flags.BoolVar(&myLists, "my-lists", false, "batch download each member of every list owned or subscribed by the main account")
+	flags.BoolVar(&fromSubscriptions, "subs", !legacy, "also download every enabled subscription")
+	flags.BoolVar(&autoFollow, "auto-follow", false, "send follow request automatically to protected users")
+	flags.BoolVar(&noRetry, "no-retry", false, "do not retry failed tweets before exiting, only dump them for \"xSync retry\"")
+
+	flags.Parse(args)
+
+	sysCfgHelper := syscfghelper.New(sysCliParams)
+	defer sysCfgHelper.Close()
+
+	////////////////////////////////////////////////////////////////////////////
+
+	logger := log.WithField("function", "main")
+	logger.Infoln("xSync started")
+
+	db := openDatabase(sysCfgHelper)
+	defer db.Close()
+	logger.Infoln("database is connected")
+
+	stopSignal := cancelOnSignal(cancel)
+	defer stopSignal()
+
+	////////////////////////////////////////////////////////////////////////////
+	// Main Job Execution
+	////////////////////////////////////////////////////////////////////////////
+
+	defer persistRateLimits(sysCfgHelper)()
+	manager, mainClient := newClientManager(ctx, sysCfgHelper)
+	defer reportApiCounts(manager)
+	defer watchAdditionalCookies(ctx, sysCfgHelper, manager)()
+
+	////////////////////////////////////////////////////////////////////////////
+
+	argHelper := arghelper.New(
+		mainClient,
+		userTwitterIdsArg,
+		userTwitterScreenNamesArg,
+		twitterListIdsArg,
+		userTwitterIdsForFollowersArg,
+	)
+	argHelper.AddFollowerMembers(followerMembersArg)
+	if myLists {
+		argHelper.AddMyLists()
+	}
+	if fromSubscriptions {
+		subs, err := subscriptionrepo.New().ListEnabled(ctx, db)
+		if err != nil {
+			logger.Fatalln("failed to list subscriptions:", err)
+		}
+		if err := argHelper.AddSubscriptions(subs); err != nil {
+			logger.Fatalln("failed to load subscriptions:", err)
+		}
+		logger.Infof("%d subscriptions loaded", len(subs))
+	}
+	titledUserList := argHelper.GetTitledUserLists(ctx)
+	if len(titledUserList) == 0 && len(searchQueriesArg) == 0 && len(communityIdsArg) == 0 {
+		logger.Warnln("no user, list, search or community specified, exiting")
+		return
+	}
+
+	syncHelper := newSyncHelper(sysCfgHelper, db, manager, autoFollow)
+	if err := syncHelper.Sync(ctx, titledUserList); err != nil {
+		logger.Fatalln(err)
+	}
+	for _, query := range searchQueriesArg {
+		if err := syncHelper.Search(ctx, query); err != nil {
+			logger.Errorln(err)
+		}
+	}
+	for _, communityId := range communityIdsArg {
+		if err := syncHelper.Community(ctx, communityId); err != nil {
+			logger.Errorln(err)
+		}
+	}
+
+	if noRetry {
+		syncHelper.Dump()
+		logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", syncHelper.FailedCount())
+		return
+	}
+
+	logger.Infoln("starting to retry failed tweets")
+	if syncHelper.FailedCount() == 0 {
+		return
+	}
+	if err := syncHelper.Retry(ctx); err != nil {
+		logger.Fatalln(err)
+	}
+
+	syncHelper.Dump()
+	logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", syncHelper.FailedCount())
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/synchelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/database"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// sysCliParams holds the config overrides shared by every command. They can be
+// given before the command, or after it for commands with their own flags.
+var sysCliParams = syscfghelper.CliParams{Overrides: make(map[string]string)}
+
+func addConfigFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&sysCliParams.IsDebug, "debug", sysCliParams.IsDebug, "display debug message")
+	flags.StringVar(&sysCliParams.ConfigPath, "config", sysCliParams.ConfigPath, "config file, defaults to $XSYNC_CONFIG or conf.yaml in the state dir")
+	flags.StringVar(&sysCliParams.StateDir, "state-dir", sysCliParams.StateDir, "dir of the config, logs and cookies, defaults to $XSYNC_STATE_DIR or $HOME/.x_sync")
+	flags.StringVar(&sysCliParams.Profile, "profile", sysCliParams.Profile, "use the config, cookies, database and logs of a named profile, defaults to $XSYNC_PROFILE")
+	flags.StringVar(&sysCliParams.RecordDir, "record", sysCliParams.RecordDir, "save the GraphQL requests and responses in a dir, without the cookies")
+	flags.StringVar(&sysCliParams.ReplayDir, "replay", sysCliParams.ReplayDir, "answer the requests from a dir saved by --record instead of x.com")
+	for _, field := range config.Fields {
+		flags.Func(field.Flag, fmt.Sprintf("%s, overrides %s of the config and $%s", field.Usage, field.Key, field.Env), func(value string) error {
+			sysCliParams.Overrides[field.Key] = value
+			return nil
+		})
+	}
+}
+
+// newFlagSet prints the description and the flags of a command on -h
+func newFlagSet(name string, usage string, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "usage: xSync %s %s\n\n%s\n\nflags:\n", name, usage, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func openDatabase(sysCfgHelper SysCfgHelper) *sqlx.DB {
+	logger := log.WithField("function", "openDatabase")
+
+	dbPath, err := sysCfgHelper.GetSqliteDBPath()
+	if err != nil {
+		logger.Fatalln("failed to get database path:", err)
+	}
+	db, err := database.ConnectDatabase(dbPath)
+	if err != nil {
+		logger.Fatalln("failed to connect to database:", err)
+	}
+	return db
+}
+
+// cancelOnSignal cancels the context on the first termination signal, the
+// returned function stops listening. SIGHUP reloads the cookies instead, see
+// watchAdditionalCookies.
+func cancelOnSignal(cancel context.CancelFunc) func() {
+	logger := log.WithField("function", "cancelOnSignal")
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	go func() {
+		sig, ok := <-sigChan
+		if ok {
+			logger.Warnln("[listener] caught signal:", sig)
+		}
+		cancel()
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(sigChan)
+	}
+}
+
+// persistRateLimits makes the clients created afterwards restore the quotas
+// of the previous runs and save theirs, the returned function writes them
+func persistRateLimits(sysCfgHelper SysCfgHelper) func() {
+	logger := log.WithField("function", "persistRateLimits")
+
+	store, err := twitterclient.NewFileRateLimitStore(sysCfgHelper.GetRateLimitsPath())
+	if err != nil {
+		logger.Warnln("failed to load the rate limits of previous runs:", err)
+		return func() {}
+	}
+	twitterclient.SetRateLimitStore(store)
+
+	return func() {
+		if err := store.Flush(); err != nil {
+			logger.Warnln("failed to save rate limits:", err)
+		}
+	}
+}
+
+// GRAPHQL_CATALOG_TIMEOUT is how long discovering the query ids may hold up the
+// start of a run
+const GRAPHQL_CATALOG_TIMEOUT = 30 * time.Second
+
+// resolveGraphQL makes the clients use the query ids and feature switches of
+// the current web client, cached in the state dir. The built-in ones are used
+// when neither the cache nor x.com has them.
+func resolveGraphQL(ctx context.Context, sysCfgHelper SysCfgHelper) {
+	logger := log.WithField("function", "resolveGraphQL")
+
+	conf := sysCfgHelper.GetConfig()
+	if conf.ApiHost != "" {
+		logger.Debugln("using the built-in GraphQL query ids with api host", conf.ApiHost)
+		return
+	}
+	// a replay matches the operations by name, whatever their query ids
+	if sysCliParams.ReplayDir != "" {
+		logger.Debugln("using the built-in GraphQL query ids while replaying")
+		return
+	}
+
+	httpClient := &http.Client{Timeout: GRAPHQL_CATALOG_TIMEOUT}
+	if proxy := cmp.Or(conf.Cookie.Proxy, conf.Proxy); proxy != "" {
+		proxyUrl, err := utils.ParseProxyUrl(proxy)
+		if err != nil {
+			logger.Warnln("using the built-in GraphQL query ids:", err)
+			return
+		}
+		httpClient.Transport = &http.Transport{Proxy: http.ProxyURL(proxyUrl)}
+	}
+
+	catalog, err := twitterclient.LoadGraphQLCatalog(
+		ctx,
+		sysCfgHelper.GetGraphQLCatalogPath(),
+		twitterclient.GRAPHQL_CATALOG_TTL,
+		func(ctx context.Context) (*twitterclient.GraphQLCatalog, error) {
+			return twitterclient.FetchGraphQLCatalog(ctx, httpClient, twitterclient.API_HOST)
+		},
+	)
+	if err != nil {
+		logger.Warnln("using the built-in GraphQL query ids:", err)
+		return
+	}
+	twitterclient.SetGraphQLCatalog(catalog)
+	logger.Debugf("using %d GraphQL operations of %s", len(catalog.Operations), catalog.FetchedAt.Format(time.DateTime))
+}
+
+// newClientManager logs in every configured account, the main client is also
+// returned for requests that must not rotate accounts
+func newClientManager(ctx context.Context, sysCfgHelper SysCfgHelper) (*twitterclient.Manager, *twitterclient.Client) {
+	logger := log.WithField("function", "newClientManager")
+
+	resolveGraphQL(ctx, sysCfgHelper)
+
+	manager := twitterclient.NewManager()
+	manager.SetBudgets(sysCfgHelper.GetBudgets())
+
+	mainClient, err := sysCfgHelper.GetMainClient(ctx)
+	if err != nil {
+		logger.Fatalln("failed to get main client:", err)
+	}
+	manager.SetMasterClient(mainClient)
+	if err := manager.AddClient(mainClient); err != nil {
+		logger.Warnln("failed to add master client to manager:", err)
+	}
+
+	additionalClients, err := sysCfgHelper.GetOtherClients(ctx)
+	if err != nil {
+		logger.Fatalln("failed to get additional clients:", err)
+	}
+	for _, additionalClient := range additionalClients {
+		if err := manager.AddClient(additionalClient); err != nil {
+			logger.Warnln("failed to add additional client to manager:", err)
+		}
+	}
+
+	return manager, mainClient
+}
+
+// COOKIES_WATCH_INTERVAL is how often long runs look for changes of the
+// additional cookies file
+const COOKIES_WATCH_INTERVAL = 30 * time.Second
+
+// watchAdditionalCookies adds and retires accounts while running, whenever
+// the additional cookies file changes or on SIGHUP. The returned function
+// stops watching.
+func watchAdditionalCookies(ctx context.Context, sysCfgHelper SysCfgHelper, manager *twitterclient.Manager) func() {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		manager.WatchFile(
+			ctx,
+			sysCfgHelper.GetAdditionalCookiesPath(),
+			COOKIES_WATCH_INTERVAL,
+			sighup,
+			func(ctx context.Context) error {
+				return sysCfgHelper.ReloadOtherClients(ctx, manager)
+			},
+		)
+	}()
+
+	return func() {
+		signal.Stop(sighup)
+		cancel()
+		<-done
+	}
+}
+
+func reportApiCounts(manager *twitterclient.Manager) {
+	logger := log.WithField("function", "main")
+	for path, count := range manager.GetApiCounts() {
+		logger.Infof("API %s called %d times", path, count)
+	}
+	for kind, count := range twitterclient.SkippedEntries() {
+		logger.Infof("skipped %d timeline entries: %s", count, kind)
+	}
+	now := time.Now()
+	for _, client := range manager.GetUsage() {
+		for _, usage := range client.Usage {
+			limits := client.Budget.LimitsOf(usage.Path)
+			logger.Infof(
+				"@%s called %s %s times this hour and %s times today",
+				client.ScreenName,
+				path.Base(usage.Path),
+				formatBudgetUsage(usage.At(now).HourCount, limits.PerHour),
+				formatBudgetUsage(usage.At(now).DayCount, limits.PerDay),
+			)
+		}
+	}
+}
+
+func newSyncHelper(sysCfgHelper SysCfgHelper, db *sqlx.DB, manager *twitterclient.Manager, autoFollow bool) SyncHelper {
+	logger := log.WithField("function", "newSyncHelper")
+
+	usersAssetsPath, err := sysCfgHelper.GetUsersAssetsPath()
+	if err != nil {
+		logger.Fatalln("failed to get users assets path:", err)
+	}
+	dumpPath, err := sysCfgHelper.GetErrorBkJsonPath()
+	if err != nil {
+		logger.Fatalln("failed to get error backup path:", err)
+	}
+
+	syncHelper, err := synchelper.New(db, manager, synchelper.Config{
+		UsersAssetsPath: usersAssetsPath,
+		DumpPath:        dumpPath,
+		Downloading:     sysCfgHelper.GetDownloadingCfg(),
+		AutoFollow:      autoFollow,
+	})
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	return syncHelper
+}
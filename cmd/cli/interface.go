@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/downloading"
+	"github.com/jmoiron/sqlx"
+)
+
+type SysCfgHelper interface {
+	GetConfig() *config.Config
+	GetSqliteDBPath() (string, error)
+	GetMainCookie() (*config.Cookie, error)
+	GetMainClient(ctx context.Context) (*twitterclient.Client, error)
+	GetOtherClients(ctx context.Context) ([]*twitterclient.Client, error)
+	GetAdditionalCookies() ([]*config.Cookie, error)
+	GetAdditionalCookiesPath() string
+	ReloadOtherClients(ctx context.Context, manager *twitterclient.Manager) error
+	GetUsersAssetsPath() (string, error)
+	GetErrorBkJsonPath() (string, error)
+	GetDownloadingCfg() downloading.Config
+	GetRateLimitsPath() string
+	GetGraphQLCatalogPath() string
+	GetBudgets() *twitterclient.Budgets
+	NewClient(ctx context.Context, cookie *config.Cookie) (*twitterclient.Client, error)
+}
+
+type SyncHelper interface {
+	Sync(ctx context.Context, titledUserLists []twitterclient.TitledUserList) error
+	Get(ctx context.Context, tweets []*twitterclient.Tweet) error
+	Search(ctx context.Context, query string) error
+	Community(ctx context.Context, communityId uint64) error
+	Retry(ctx context.Context) error
+	Dump() error
+	FailedCount() int
+}
+
+type SubscriptionRepo interface {
+	ListEnabled(ctx context.Context, db *sqlx.DB) ([]*model.Subscription, error)
+	MarkSynced(ctx context.Context, db *sqlx.DB, id int64, at time.Time) error
+}
@@ -1,201 +1,100 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
-
-	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/arghelper"
-	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/metahelper"
-	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
-	"github.com/WangWilly/xSync/pkgs/commonpkg/database"
-	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
-	"github.com/WangWilly/xSync/pkgs/downloading"
-	"github.com/WangWilly/xSync/pkgs/downloading/heaphelper"
-	"github.com/WangWilly/xSync/pkgs/downloading/resolveworker"
-	log "github.com/sirupsen/logrus"
+	"text/tabwriter"
 )
 
-func main() {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	var userTwitterIdsArg arghelper.UserTwitterIdsArg
-	var userTwitterScreenNamesArg arghelper.UserTwitterScreenNamesArg
-	var twitterListIdsArg arghelper.TwitterListIdsArg
-	var userTwitterIdsForFollowersArg arghelper.UserTwitterIdsArg
-	flag.Var(&userTwitterIdsArg, "user", "download tweets from the user specified by user_id since the last download")
-	flag.Var(&userTwitterScreenNamesArg, "user-name", "download tweets from the user specified by screen_name since the last download")
-	flag.Var(&twitterListIdsArg, "list", "batch download each member from list specified by list_id")
-	flag.Var(&userTwitterIdsForFollowersArg, "foll", "batch download each member followed by the user specified by user_id")
-
-	sysCliParams := syscfghelper.CliParams{}
-	flag.BoolVar(&sysCliParams.ConfOverWrite, "conf", false, "reconfigure")
-	flag.BoolVar(&sysCliParams.IsDebug, "debug", false, "display debug message")
-
-	var autoFollow bool
-	var noRetry bool
-	flag.BoolVar(&autoFollow, "auto-follow", false, "send follow request automatically to protected users")
-	flag.BoolVar(&noRetry, "no-retry", false, "quickly exit without retrying failed tweets")
-
-	flag.Parse()
-
-	sysCfgHelper := syscfghelper.New(sysCliParams)
-	defer sysCfgHelper.Close()
-
-	////////////////////////////////////////////////////////////////////////////
-
-	logger := log.WithField("function", "main")
-	logger.Infoln("xSync started")
-
-	////////////////////////////////////////////////////////////////////////////
-
-	dbPath, err := sysCfgHelper.GetSqliteDBPath()
-	if err != nil {
-		logger.Fatalln("failed to get database path:", err)
-	}
-	db, err := database.ConnectDatabase(dbPath)
-	if err != nil {
-		logger.Fatalln("failed to connect to database:", err)
-	}
-	defer db.Close()
-	logger.Infoln("database is connected")
-
-	////////////////////////////////////////////////////////////////////////////
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	defer close(sigChan)
-	defer signal.Stop(sigChan)
-	go func() {
-		sig, ok := <-sigChan
-		if ok {
-			logger.Warnln("[listener] caught signal:", sig)
-		}
-		cancel()
-	}()
-
-	////////////////////////////////////////////////////////////////////////////
-	// Main Job Execution
-	////////////////////////////////////////////////////////////////////////////
-
-	manager := twitterclient.NewManager()
-	defer func() { // report at exit
-		for path, count := range manager.GetApiCounts() {
-			logger.Infof("API %s called %d times", path, count)
-		}
-	}()
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
 
-	mainClient, err := sysCfgHelper.GetMainClient(ctx)
-	if err != nil {
-		logger.Fatalln("failed to get main client:", err)
-	}
-	manager.SetMasterClient(mainClient)
-	if err := manager.AddClient(mainClient); err != nil {
-		logger.Warnln("failed to add master client to manager:", err)
+func commands() []command {
+	return []command{
+		{"sync", "download the subscriptions and the targets given by flags", func(args []string) { runSync("sync", args, false) }},
+		{"get", "download single tweets or threads by their URLs or ids", runGet},
+		{"retry", "download the tweets that failed in previous runs", runRetry},
+		{"status", "show the last sync and counts of every user", runStatus},
+		{"sub", "manage the subscriptions synced by sync and daemon", runSub},
+		{"daemon", "keep re-syncing the subscriptions on their schedules", runDaemon},
+		{"config", "show or reconfigure the configuration", runConfig},
+		{"profile", "manage profiles with separate configs, cookies and databases", runProfile},
+		{"doctor", "check the accounts, storage and database", runDoctor},
+		{"db", "inspect and maintain the database", runDb},
+		{"export", "export users, tweets or subscriptions as json or csv", runExport},
+		{"serve", "start the web dashboard", runServe},
 	}
+}
 
-	additionalClients, err := sysCfgHelper.GetOtherClients(ctx)
-	if err != nil {
-		logger.Fatalln("failed to get additional clients:", err)
-	}
-	for _, additionalClient := range additionalClients {
-		if err := manager.AddClient(additionalClient); err != nil {
-			logger.Warnln("failed to add additional client to manager:", err)
+func main() {
+	args := os.Args[1:]
+
+	global := flag.NewFlagSet("xSync", flag.ContinueOnError)
+	global.SetOutput(io.Discard)
+	addConfigFlags(global)
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printUsage(os.Stdout)
+			return
 		}
-	}
-
-	////////////////////////////////////////////////////////////////////////////
-
-	argHelper := arghelper.New(
-		mainClient,
-		userTwitterIdsArg,
-		userTwitterScreenNamesArg,
-		twitterListIdsArg,
-		userTwitterIdsForFollowersArg,
-	)
-	titledUserList := argHelper.GetTitledUserLists(ctx)
-	if err != nil {
-		logger.Fatalln("failed to get titled user lists:", err)
-	}
-	if len(titledUserList) == 0 {
-		logger.Warnln("no user or list specified, exiting")
+		// flags without a command are the download flags of old versions
+		runSync("sync", args, true)
 		return
 	}
+	args = global.Args()
 
-	metahelper := metahelper.New(db, manager)
-	if err := metahelper.SaveToDb(ctx, titledUserList); err != nil {
-		logger.Fatalln("failed to save meta data to database:", err)
-	}
-	usersAssetsPath, err := sysCfgHelper.GetUsersAssetsPath()
-	if err != nil {
-		logger.Fatalln("failed to get users assets path:", err)
-	}
-	if err := metahelper.SaveToStorage(ctx, usersAssetsPath, titledUserList); err != nil {
-		logger.Fatalln("failed to save meta data to storage:", err)
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		os.Exit(2)
 	}
 
-	if autoFollow {
-		metahelper.DoFollow(ctx, titledUserList)
-	}
-
-	////////////////////////////////////////////////////////////////////////////
-
-	smartPaths := metahelper.ToUserSmartPaths(ctx, titledUserList)
-	heapHelper, err := heaphelper.New(titledUserList, smartPaths)
-	if err != nil {
-		logger.Fatalln(err)
-	}
-	dbWorker := resolveworker.NewDBWorker(db, manager, heapHelper)
-	downloadHelper := downloading.NewDownloadHelperWithConfig(
-		sysCfgHelper.GetDownloadingCfg(),
-		dbWorker,
-	)
-
-	dumper := downloading.NewDumper(db)
-	dumpPath, err := sysCfgHelper.GetErrorBkJsonPath()
-	if err != nil {
-		logger.Fatalln("failed to get error backup path:", err)
-	}
-	if err := dumper.Load(dumpPath); err != nil {
-		logger.Fatalln("failed to load previous tweets", err)
-	}
-
-	////////////////////////////////////////////////////////////////////////////
-
-	toDump, err := downloadHelper.BatchUserDownloadWithDB(ctx)
-	if err != nil {
-		logger.Errorln("failed to download:", err)
-	}
-	for _, te := range toDump {
-		dumper.Push(te.GetUserSmartPath().Id(), te.GetTweet())
-	}
-
-	if ctx.Err() == context.Canceled && noRetry {
-		dumper.Dump(dumpPath)
-		logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", dumper.Count())
-		return
-	}
-
-	logger.Infoln("starting to retry failed tweets")
-	if dumper.Count() == 0 {
+	if name := args[0]; name == "help" {
+		if len(args) > 1 {
+			runCommand(args[1], []string{"-h"})
+			return
+		}
+		printUsage(os.Stdout)
 		return
 	}
-	retrible, err := dumper.ListAll(ctx)
-	if err != nil {
-		logger.Fatalln("failed to list all failed tweets:", err)
-	}
 
-	newFails := downloadHelper.BatchDownloadTweetWithDB(ctx, retrible...)
-	dumper.Clear()
+	runCommand(args[0], args[1:])
+}
 
-	for _, pt := range newFails {
-		te := pt
-		dumper.Push(te.Entity.Id(), te.Tweet)
+func runCommand(name string, args []string) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			cmd.run(args)
+			return
+		}
 	}
 
-	dumper.Dump(dumpPath)
-	logger.Infof("%d tweets have been dumped and will be downloaded the next time the program runs", dumper.Count())
+	fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", name)
+	printUsage(os.Stderr)
+	os.Exit(2)
+}
+
+func printUsage(w *os.File) {
+	fmt.Fprintln(w, "usage: xSync [global flags] <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands() {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "global flags:")
+	global := flag.NewFlagSet("xSync", flag.ContinueOnError)
+	global.SetOutput(w)
+	addConfigFlags(global)
+	global.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "xSync help <command>" for the flags of a command`)
+	fmt.Fprintln(w, `"xSync --user ..." without a command still works and is the same as "xSync sync --subs=false --user ..."`)
 }
@@ -1,18 +1,45 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 
+	"github.com/WangWilly/xSync/pkgs/commonpkg/helpers/syscfghelper"
 	"github.com/WangWilly/xSync/pkgs/serverpkg/server"
 )
 
 func main() {
+	var dbPath string
+	var profile string
+	var stateDir string
+	flag.StringVar(&dbPath, "db", "./conf/data/xSync.db", "database to serve")
+	flag.StringVar(&profile, "profile", os.Getenv(syscfghelper.PROFILE_ENV), "serve the database of this profile instead of --db")
+	flag.StringVar(&stateDir, "state-dir", "", "state dir of the profiles, defaults to $XSYNC_STATE_DIR or $HOME/.x_sync")
+	flag.Parse()
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
-	dbPath := "./conf/data/xSync.db"
+
+	if profile != "" {
+		sysCfgHelper := syscfghelper.New(syscfghelper.CliParams{
+			Profile:    profile,
+			StateDir:   stateDir,
+			NoValidate: true,
+		})
+		defer sysCfgHelper.Close()
+
+		if sysCfgHelper.GetConfig().RootPath == "" {
+			log.Fatalf("profile %s has no root_path configured", profile)
+		}
+		p, err := sysCfgHelper.GetSqliteDBPath()
+		if err != nil {
+			log.Fatal("Failed to get database path:", err)
+		}
+		dbPath = p
+	}
 
 	srv, err := server.NewServer(dbPath, port)
 	if err != nil {
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// EndpointBudget caps the requests of an account to an endpoint, 0 is no cap
+type EndpointBudget struct {
+	PerHour int `yaml:"per_hour,omitempty"`
+	PerDay  int `yaml:"per_day,omitempty"`
+}
+
+// Budget caps the requests of an account to each endpoint
+type Budget struct {
+	EndpointBudget `yaml:",inline"`
+	// Endpoints override the caps of single endpoints, by the last part of
+	// their path such as UserMedia
+	Endpoints map[string]EndpointBudget `yaml:"endpoints,omitempty"`
+}
+
+// Budgets keep the accounts under the radar, on top of the rate limits
+// reported by Twitter
+type Budgets struct {
+	// Additional applies to every additional account without its own budget
+	Additional *Budget `yaml:"additional,omitempty"`
+	// Accounts are budgets by screen name, the main account included
+	Accounts map[string]*Budget `yaml:"accounts,omitempty"`
+}
+
+// additionalBudget returns the budget of the additional accounts, created
+// when missing so a field can be set on it
+func (c *Config) additionalBudget() *Budget {
+	if c.Budgets == nil {
+		c.Budgets = &Budgets{}
+	}
+	if c.Budgets.Additional == nil {
+		c.Budgets.Additional = &Budget{}
+	}
+	return c.Budgets.Additional
+}
+
+func getBudgetCap(conf *Config, get func(b *Budget) int) string {
+	if conf.Budgets == nil || conf.Budgets.Additional == nil {
+		return "0"
+	}
+	return strconv.Itoa(get(conf.Budgets.Additional))
+}
+
+func parseBudgetCap(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("must be a non-negative integer, got %q", value)
+	}
+	return n, nil
+}
+
+func (b *EndpointBudget) validate(name string) []error {
+	var errs []error
+	if b.PerHour < 0 {
+		errs = append(errs, fmt.Errorf("%s.per_hour must not be negative, got %d", name, b.PerHour))
+	}
+	if b.PerDay < 0 {
+		errs = append(errs, fmt.Errorf("%s.per_day must not be negative, got %d", name, b.PerDay))
+	}
+	return errs
+}
+
+func (b *Budget) validate(name string) []error {
+	if b == nil {
+		return nil
+	}
+	errs := b.EndpointBudget.validate(name)
+	for endpoint, limits := range b.Endpoints {
+		errs = append(errs, limits.validate(name+".endpoints."+endpoint)...)
+	}
+	return errs
+}
+
+func (b *Budgets) validate() []error {
+	if b == nil {
+		return nil
+	}
+	errs := b.Additional.validate("budgets.additional")
+	for screenName, budget := range b.Accounts {
+		errs = append(errs, budget.validate("budgets.accounts."+screenName)...)
+	}
+	return errs
+}
@@ -15,6 +15,9 @@ import (
 type Cookie struct {
 	AuthToken string `yaml:"auth_token"`
 	Ct0       string `yaml:"ct0"`
+	// Proxy is the http, https or socks5 URL the account requests through,
+	// over the proxy of the config
+	Proxy string `yaml:"proxy,omitempty"`
 }
 
 // Config represents the main application configuration
@@ -22,6 +25,18 @@ type Config struct {
 	RootPath           string `yaml:"root_path"`
 	Cookie             Cookie `yaml:"cookie"`
 	MaxDownloadRoutine int    `yaml:"max_download_routine"`
+
+	// Proxy applies to every account without its own, MediaProxy to the
+	// downloads from the media CDN
+	Proxy      string `yaml:"proxy,omitempty"`
+	MediaProxy string `yaml:"media_proxy,omitempty"`
+
+	// ApiHost and MediaHost replace x.com and its media CDN, to run against
+	// a fake of them
+	ApiHost   string `yaml:"api_host,omitempty"`
+	MediaHost string `yaml:"media_host,omitempty"`
+
+	Budgets *Budgets `yaml:"budgets,omitempty"`
 }
 
 // ParseConfigFromFile reads configuration from the specified path
@@ -45,9 +60,24 @@ func ParseConfigFromFile(path string) (*Config, error) {
 	return &result, nil
 }
 
+// openPrivateFile truncates a file for writing, readable by the owner only
+// since it holds session cookies. Files created by older versions are
+// tightened too.
+func openPrivateFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
 // WriteConfig writes configuration to the specified path
 func WriteConfig(path string, conf *Config) error {
-	file, err := os.OpenFile(path, os.O_TRUNC|os.O_WRONLY|os.O_CREATE, 0666)
+	file, err := openPrivateFile(path)
 	if err != nil {
 		return err
 	}
@@ -61,8 +91,9 @@ func WriteConfig(path string, conf *Config) error {
 	return err
 }
 
-// PromptConfig interactively prompts user for configuration and saves it
-func PromptConfig(saveto string) (*Config, error) {
+// PromptConfig interactively prompts user for configuration and saves it,
+// with the cookie encrypted when a cipher is given
+func PromptConfig(saveto string, cipher *Cipher) (*Config, error) {
 	conf := Config{}
 	scan := bufio.NewScanner(os.Stdin)
 
@@ -96,7 +127,14 @@ func PromptConfig(saveto string) (*Config, error) {
 		return nil, err
 	}
 
-	return &conf, WriteConfig(saveto, &conf)
+	if cipher == nil {
+		return &conf, WriteConfig(saveto, &conf)
+	}
+	sealed := conf
+	if err := cipher.EncryptCookie(&sealed.Cookie); err != nil {
+		return nil, err
+	}
+	return &conf, WriteConfig(saveto, &sealed)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -122,3 +160,19 @@ func ReadAdditionalCookies(path string) ([]*Cookie, error) {
 
 	return res, yaml.Unmarshal(data, &res)
 }
+
+// WriteAdditionalCookies replaces the additional cookies at the specified path
+func WriteAdditionalCookies(path string, cookies []*Cookie) error {
+	file, err := openPrivateFile(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := yaml.Marshal(cookies)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, bytes.NewReader(data))
+	return err
+}
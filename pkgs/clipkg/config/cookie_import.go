@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Cookie Import
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	COOKIE_NAME_AUTH_TOKEN = "auth_token"
+	COOKIE_NAME_CT0        = "ct0"
+)
+
+var cookieDomains = []string{"x.com", "twitter.com"}
+
+// rawCookie is a single browser cookie, the fields are shared by the JSON
+// exports of the common browser extensions
+type rawCookie struct {
+	Domain string `json:"domain"`
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+}
+
+// ParseCookieExport extracts the accounts of a Netscape cookies.txt or a JSON
+// cookie export. Only x.com and twitter.com cookies are kept, one account per
+// domain holding both an auth_token and a ct0.
+func ParseCookieExport(data []byte) ([]*Cookie, error) {
+	var raws []*rawCookie
+	var err error
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		raws, err = parseJsonCookies(trimmed)
+	} else {
+		raws, err = parseNetscapeCookies(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byDomain := make(map[string]*Cookie)
+	domains := make([]string, 0)
+	for _, raw := range raws {
+		domain, ok := matchCookieDomain(raw.Domain)
+		if !ok {
+			continue
+		}
+		cookie, ok := byDomain[domain]
+		if !ok {
+			cookie = &Cookie{}
+			byDomain[domain] = cookie
+			domains = append(domains, domain)
+		}
+		switch raw.Name {
+		case COOKIE_NAME_AUTH_TOKEN:
+			cookie.AuthToken = raw.Value
+		case COOKIE_NAME_CT0:
+			cookie.Ct0 = raw.Value
+		}
+	}
+
+	res := make([]*Cookie, 0, len(domains))
+	seen := make(map[string]struct{})
+	for _, domain := range domains {
+		cookie := byDomain[domain]
+		if cookie.AuthToken == "" || cookie.Ct0 == "" {
+			continue
+		}
+		if _, ok := seen[cookie.AuthToken]; ok {
+			continue
+		}
+		seen[cookie.AuthToken] = struct{}{}
+		res = append(res, cookie)
+	}
+	return res, nil
+}
+
+func matchCookieDomain(domain string) (string, bool) {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	for _, d := range cookieDomains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// parseNetscapeCookies reads the tab separated cookies.txt format: domain,
+// include subdomains, path, secure, expiry, name and value
+func parseNetscapeCookies(data []byte) ([]*rawCookie, error) {
+	res := make([]*rawCookie, 0)
+
+	scan := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scan.Scan(); lineNo++ {
+		line := strings.TrimRight(scan.Text(), "\r")
+		// curl marks http only cookies with this prefix
+		line = strings.TrimPrefix(line, "#HttpOnly_")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("invalid cookies.txt line %d: expected 7 tab separated fields, got %d", lineNo, len(fields))
+		}
+		res = append(res, &rawCookie{
+			Domain: fields[0],
+			Name:   fields[5],
+			Value:  fields[6],
+		})
+	}
+	return res, scan.Err()
+}
+
+// parseJsonCookies reads an array of cookies, or an object holding them in
+// "cookies" like the storage state of browser automation tools
+func parseJsonCookies(data []byte) ([]*rawCookie, error) {
+	var res []*rawCookie
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf("invalid JSON cookie export: %w", err)
+		}
+		return res, nil
+	}
+
+	var wrapped struct {
+		Cookies []*rawCookie `json:"cookies"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid JSON cookie export: %w", err)
+	}
+	return wrapped.Cookies, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCookieExportNetscape(t *testing.T) {
+	data := []byte("# Netscape HTTP Cookie File\n" +
+		"\n" +
+		".x.com\tTRUE\t/\tTRUE\t1893456000\tct0\tcsrf1\n" +
+		"#HttpOnly_.x.com\tTRUE\t/\tTRUE\t1893456000\tauth_token\ttoken1\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tauth_token\tother\r\n" +
+		"twitter.com\tFALSE\t/\tTRUE\t0\tauth_token\ttoken2\n")
+
+	cookies, err := ParseCookieExport(data)
+
+	require.NoError(t, err)
+	require.Len(t, cookies, 1)
+	assert.Equal(t, &Cookie{AuthToken: "token1", Ct0: "csrf1"}, cookies[0])
+}
+
+func TestParseCookieExportNetscapeInvalid(t *testing.T) {
+	_, err := ParseCookieExport([]byte(".x.com\tTRUE\t/\n"))
+
+	assert.ErrorContains(t, err, "line 1")
+}
+
+func TestParseCookieExportJson(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{
+			"array",
+			`[
+				{"domain": ".x.com", "name": "auth_token", "value": "token1", "httpOnly": true},
+				{"domain": ".x.com", "name": "ct0", "value": "csrf1"},
+				{"domain": ".twitter.com", "name": "auth_token", "value": "token2"},
+				{"domain": "api.twitter.com", "name": "ct0", "value": "csrf2"},
+				{"domain": "x.company.com", "name": "ct0", "value": "nope"}
+			]`,
+		},
+		{
+			"storage state",
+			`{"cookies": [
+				{"domain": ".x.com", "name": "auth_token", "value": "token1"},
+				{"domain": ".x.com", "name": "ct0", "value": "csrf1"},
+				{"domain": ".twitter.com", "name": "auth_token", "value": "token2"},
+				{"domain": ".twitter.com", "name": "ct0", "value": "csrf2"}
+			], "origins": []}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cookies, err := ParseCookieExport([]byte(test.data))
+
+			require.NoError(t, err)
+			assert.Equal(t, []*Cookie{
+				{AuthToken: "token1", Ct0: "csrf1"},
+				{AuthToken: "token2", Ct0: "csrf2"},
+			}, cookies)
+		})
+	}
+}
+
+func TestParseCookieExportDuplicateToken(t *testing.T) {
+	data := `[
+		{"domain": ".x.com", "name": "auth_token", "value": "token1"},
+		{"domain": ".x.com", "name": "ct0", "value": "csrf1"},
+		{"domain": ".twitter.com", "name": "auth_token", "value": "token1"},
+		{"domain": ".twitter.com", "name": "ct0", "value": "csrf1"}
+	]`
+
+	cookies, err := ParseCookieExport([]byte(data))
+
+	require.NoError(t, err)
+	assert.Len(t, cookies, 1)
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+)
+
+// Field is a single configuration item, settable by its key, its XSYNC_*
+// environment variable or its command line flag
+type Field struct {
+	Key   string
+	Env   string
+	Flag  string
+	Usage string
+
+	get func(conf *Config) string
+	set func(conf *Config, value string) error
+}
+
+var Fields = []*Field{
+	{
+		Key:   "root_path",
+		Env:   "XSYNC_ROOT_PATH",
+		Flag:  "root-path",
+		Usage: "storage dir of the database and the downloaded users",
+		get:   func(conf *Config) string { return conf.RootPath },
+		set: func(conf *Config, value string) error {
+			if value == "" {
+				conf.RootPath = ""
+				return nil
+			}
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return err
+			}
+			conf.RootPath = abs
+			return nil
+		},
+	},
+	{
+		Key:   "cookie.auth_token",
+		Env:   "XSYNC_AUTH_TOKEN",
+		Flag:  "auth-token",
+		Usage: "auth_token cookie of the main account",
+		get:   func(conf *Config) string { return conf.Cookie.AuthToken },
+		set: func(conf *Config, value string) error {
+			conf.Cookie.AuthToken = value
+			return nil
+		},
+	},
+	{
+		Key:   "cookie.ct0",
+		Env:   "XSYNC_CT0",
+		Flag:  "ct0",
+		Usage: "ct0 cookie of the main account",
+		get:   func(conf *Config) string { return conf.Cookie.Ct0 },
+		set: func(conf *Config, value string) error {
+			conf.Cookie.Ct0 = value
+			return nil
+		},
+	},
+	{
+		Key:   "cookie.proxy",
+		Env:   "XSYNC_COOKIE_PROXY",
+		Flag:  "cookie-proxy",
+		Usage: "http, https or socks5 proxy URL of the main account, over proxy",
+		get:   func(conf *Config) string { return conf.Cookie.Proxy },
+		set: func(conf *Config, value string) error {
+			if err := validateProxy(value); err != nil {
+				return err
+			}
+			conf.Cookie.Proxy = value
+			return nil
+		},
+	},
+	{
+		Key:   "proxy",
+		Env:   "XSYNC_PROXY",
+		Flag:  "proxy",
+		Usage: "http, https or socks5 proxy URL of every account without its own, instead of HTTP(S)_PROXY",
+		get:   func(conf *Config) string { return conf.Proxy },
+		set: func(conf *Config, value string) error {
+			if err := validateProxy(value); err != nil {
+				return err
+			}
+			conf.Proxy = value
+			return nil
+		},
+	},
+	{
+		Key:   "media_proxy",
+		Env:   "XSYNC_MEDIA_PROXY",
+		Flag:  "media-proxy",
+		Usage: "http, https or socks5 proxy URL of the media downloads, defaults to the proxy of the account",
+		get:   func(conf *Config) string { return conf.MediaProxy },
+		set: func(conf *Config, value string) error {
+			if err := validateProxy(value); err != nil {
+				return err
+			}
+			conf.MediaProxy = value
+			return nil
+		},
+	},
+	{
+		Key:   "api_host",
+		Env:   "XSYNC_API_HOST",
+		Flag:  "api-host",
+		Usage: "scheme and host replacing https://x.com, for testing against a fake server",
+		get:   func(conf *Config) string { return conf.ApiHost },
+		set: func(conf *Config, value string) error {
+			if err := validateHost(value); err != nil {
+				return err
+			}
+			conf.ApiHost = value
+			return nil
+		},
+	},
+	{
+		Key:   "media_host",
+		Env:   "XSYNC_MEDIA_HOST",
+		Flag:  "media-host",
+		Usage: "scheme and host replacing the media CDN, for testing against a fake server",
+		get:   func(conf *Config) string { return conf.MediaHost },
+		set: func(conf *Config, value string) error {
+			if err := validateHost(value); err != nil {
+				return err
+			}
+			conf.MediaHost = value
+			return nil
+		},
+	},
+	{
+		Key:   "max_download_routine",
+		Env:   "XSYNC_MAX_DOWNLOAD_ROUTINE",
+		Flag:  "max-download-routine",
+		Usage: "maximum concurrent downloads, 0 uses the default",
+		get:   func(conf *Config) string { return strconv.Itoa(conf.MaxDownloadRoutine) },
+		set: func(conf *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("must be a non-negative integer, got %q", value)
+			}
+			conf.MaxDownloadRoutine = n
+			return nil
+		},
+	},
+	{
+		Key:   "budgets.additional.per_hour",
+		Env:   "XSYNC_BUDGET_PER_HOUR",
+		Flag:  "budget-per-hour",
+		Usage: "requests an additional account may make to each endpoint per hour, 0 is no cap",
+		get:   func(conf *Config) string { return getBudgetCap(conf, func(b *Budget) int { return b.PerHour }) },
+		set: func(conf *Config, value string) error {
+			n, err := parseBudgetCap(value)
+			if err != nil {
+				return err
+			}
+			conf.additionalBudget().PerHour = n
+			return nil
+		},
+	},
+	{
+		Key:   "budgets.additional.per_day",
+		Env:   "XSYNC_BUDGET_PER_DAY",
+		Flag:  "budget-per-day",
+		Usage: "requests an additional account may make to each endpoint per day, 0 is no cap",
+		get:   func(conf *Config) string { return getBudgetCap(conf, func(b *Budget) int { return b.PerDay }) },
+		set: func(conf *Config, value string) error {
+			n, err := parseBudgetCap(value)
+			if err != nil {
+				return err
+			}
+			conf.additionalBudget().PerDay = n
+			return nil
+		},
+	},
+}
+
+func GetField(key string) (*Field, error) {
+	for _, field := range Fields {
+		if field.Key == key {
+			return field, nil
+		}
+	}
+
+	keys := make([]string, 0, len(Fields))
+	for _, field := range Fields {
+		keys = append(keys, field.Key)
+	}
+	return nil, fmt.Errorf("unknown config key %q, valid keys are: %s", key, strings.Join(keys, ", "))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (c *Config) Get(key string) (string, error) {
+	field, err := GetField(key)
+	if err != nil {
+		return "", err
+	}
+	return field.get(c), nil
+}
+
+func (c *Config) Set(key string, value string) error {
+	field, err := GetField(key)
+	if err != nil {
+		return err
+	}
+	if err := field.set(c, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+// ApplyEnv overrides the fields whose XSYNC_* variable is set
+func (c *Config) ApplyEnv(lookupEnv func(string) (string, bool)) error {
+	for _, field := range Fields {
+		value, ok := lookupEnv(field.Env)
+		if !ok {
+			continue
+		}
+		if err := field.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", field.Env, err)
+		}
+	}
+	return nil
+}
+
+// Validate reports every missing or invalid field and how to set it
+func (c *Config) Validate() error {
+	var errs []error
+
+	required := func(key string, value string) {
+		if value != "" {
+			return
+		}
+		field, _ := GetField(key)
+		errs = append(errs, fmt.Errorf(
+			"%s is required: set it with --%s, %s or \"xSync config set %s <value>\"",
+			field.Key, field.Flag, field.Env, field.Key,
+		))
+	}
+	required("root_path", c.RootPath)
+	required("cookie.auth_token", c.Cookie.AuthToken)
+	required("cookie.ct0", c.Cookie.Ct0)
+
+	if c.MaxDownloadRoutine < 0 {
+		errs = append(errs, fmt.Errorf("max_download_routine must not be negative, got %d", c.MaxDownloadRoutine))
+	}
+	errs = append(errs, c.Budgets.validate()...)
+
+	proxies := []struct {
+		key   string
+		value string
+	}{
+		{"cookie.proxy", c.Cookie.Proxy},
+		{"proxy", c.Proxy},
+		{"media_proxy", c.MediaProxy},
+	}
+	for _, proxy := range proxies {
+		if err := validateProxy(proxy.value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", proxy.key, err))
+		}
+	}
+	if err := validateHost(c.ApiHost); err != nil {
+		errs = append(errs, fmt.Errorf("invalid api_host: %w", err))
+	}
+	if err := validateHost(c.MediaHost); err != nil {
+		errs = append(errs, fmt.Errorf("invalid media_host: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateProxy(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := utils.ParseProxyUrl(value)
+	return err
+}
+
+func validateHost(value string) error {
+	if value == "" {
+		return nil
+	}
+	_, err := utils.ParseHostUrl(value)
+	return err
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigSet(t *testing.T) {
+	conf := &Config{}
+
+	require.NoError(t, conf.Set("cookie.ct0", "abc"))
+	assert.Equal(t, "abc", conf.Cookie.Ct0)
+
+	require.NoError(t, conf.Set("max_download_routine", "8"))
+	assert.Equal(t, 8, conf.MaxDownloadRoutine)
+
+	require.NoError(t, conf.Set("root_path", "data"))
+	assert.True(t, filepath.IsAbs(conf.RootPath))
+
+	assert.Error(t, conf.Set("max_download_routine", "-1"))
+	assert.Error(t, conf.Set("max_download_routine", "many"))
+	assert.Error(t, conf.Set("unknown", "1"))
+
+	require.NoError(t, conf.Set("api_host", "http://127.0.0.1:8080"))
+	assert.Equal(t, "http://127.0.0.1:8080", conf.ApiHost)
+	assert.Error(t, conf.Set("media_host", "127.0.0.1:8080"))
+
+	value, err := conf.Get("cookie.ct0")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", value)
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"XSYNC_AUTH_TOKEN":           "token",
+		"XSYNC_MAX_DOWNLOAD_ROUTINE": "4",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	conf := &Config{Cookie: Cookie{AuthToken: "old", Ct0: "ct0"}}
+	require.NoError(t, conf.ApplyEnv(lookupEnv))
+	assert.Equal(t, "token", conf.Cookie.AuthToken)
+	assert.Equal(t, "ct0", conf.Cookie.Ct0)
+	assert.Equal(t, 4, conf.MaxDownloadRoutine)
+
+	env["XSYNC_MAX_DOWNLOAD_ROUTINE"] = "x"
+	assert.ErrorContains(t, conf.ApplyEnv(lookupEnv), "XSYNC_MAX_DOWNLOAD_ROUTINE")
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{Cookie: Cookie{Ct0: "ct0"}}
+
+	err := conf.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "root_path is required")
+	assert.ErrorContains(t, err, "XSYNC_AUTH_TOKEN")
+	assert.NotContains(t, err.Error(), "cookie.ct0")
+
+	conf.RootPath = "/data"
+	conf.Cookie.AuthToken = "token"
+	assert.NoError(t, conf.Validate())
+}
+
+func TestConfigBudgets(t *testing.T) {
+	conf := &Config{}
+	value, err := conf.Get("budgets.additional.per_day")
+	require.NoError(t, err)
+	assert.Equal(t, "0", value)
+
+	require.NoError(t, conf.Set("budgets.additional.per_day", "500"))
+	require.NoError(t, conf.Set("budgets.additional.per_hour", "50"))
+	assert.Equal(t, EndpointBudget{PerHour: 50, PerDay: 500}, conf.Budgets.Additional.EndpointBudget)
+	assert.Error(t, conf.Set("budgets.additional.per_hour", "-1"))
+
+	data := []byte(`
+budgets:
+  additional:
+    per_day: 300
+    endpoints:
+      UserMedia:
+        per_hour: -5
+  accounts:
+    alice:
+      per_hour: 20
+`)
+	require.NoError(t, yaml.Unmarshal(data, conf))
+	assert.Equal(t, 300, conf.Budgets.Additional.PerDay)
+	assert.Equal(t, 20, conf.Budgets.Accounts["alice"].PerHour)
+
+	conf.RootPath = "/data"
+	conf.Cookie = Cookie{AuthToken: "token", Ct0: "ct0"}
+	assert.ErrorContains(t, conf.Validate(), "budgets.additional.endpoints.UserMedia.per_hour must not be negative")
+}
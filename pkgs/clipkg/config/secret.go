@@ -0,0 +1,164 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Cookie Encryption
+////////////////////////////////////////////////////////////////////////////////
+
+// ENCRYPTED_PREFIX marks an encrypted value, followed by the base64 of the
+// scrypt salt, the AES-GCM nonce and the sealed value
+const ENCRYPTED_PREFIX = "enc:v1:"
+
+const (
+	saltSize = 16
+	keySize  = 32
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var ErrWrongPassphrase = errors.New("failed to decrypt cookie, wrong passphrase or corrupted value")
+
+// IsEncrypted reports whether a value was written by Cipher.Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ENCRYPTED_PREFIX)
+}
+
+// IsEncrypted reports whether any field of the cookie is encrypted
+func (c *Cookie) IsEncrypted() bool {
+	return IsEncrypted(c.AuthToken) || IsEncrypted(c.Ct0)
+}
+
+// CookiesEncrypted reports whether any of the cookies is encrypted
+func CookiesEncrypted(cookies []*Cookie) bool {
+	for _, cookie := range cookies {
+		if cookie.IsEncrypted() {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Cipher encrypts values with a key derived from a passphrase. The values it
+// encrypts share one salt, so a file is decrypted with a single derivation.
+type Cipher struct {
+	passphrase []byte
+	salt       []byte
+	keys       map[string][]byte
+}
+
+func NewCipher(passphrase string) (*Cipher, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return &Cipher{
+		passphrase: []byte(passphrase),
+		salt:       salt,
+		keys:       make(map[string][]byte),
+	}, nil
+}
+
+// Encrypt seals a value, empty and already encrypted values are kept as is
+func (c *Cipher) Encrypt(value string) (string, error) {
+	if value == "" || IsEncrypted(value) {
+		return value, nil
+	}
+
+	aead, err := c.aead(c.salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	data := make([]byte, 0, len(c.salt)+len(nonce)+len(value)+aead.Overhead())
+	data = append(data, c.salt...)
+	data = append(data, nonce...)
+	data = aead.Seal(data, nonce, []byte(value), nil)
+	return ENCRYPTED_PREFIX + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt opens a value, plaintext values are returned as is
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, ENCRYPTED_PREFIX))
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	if len(data) < saltSize {
+		return "", ErrWrongPassphrase
+	}
+
+	aead, err := c.aead(data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return "", ErrWrongPassphrase
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+func (c *Cipher) EncryptCookie(cookie *Cookie) (err error) {
+	if cookie.AuthToken, err = c.Encrypt(cookie.AuthToken); err != nil {
+		return err
+	}
+	cookie.Ct0, err = c.Encrypt(cookie.Ct0)
+	return err
+}
+
+func (c *Cipher) DecryptCookie(cookie *Cookie) (err error) {
+	if cookie.AuthToken, err = c.Decrypt(cookie.AuthToken); err != nil {
+		return err
+	}
+	cookie.Ct0, err = c.Decrypt(cookie.Ct0)
+	return err
+}
+
+func (c *Cipher) aead(salt []byte) (cipher.AEAD, error) {
+	key, ok := c.keys[string(salt)]
+	if !ok {
+		var err error
+		key, err = scrypt.Key(c.passphrase, salt, scryptN, scryptR, scryptP, keySize)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[string(salt)] = key
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
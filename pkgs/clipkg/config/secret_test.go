@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	c, err := NewCipher("passphrase")
+	require.NoError(t, err)
+
+	cookie := &Cookie{AuthToken: "token", Ct0: "csrf"}
+	require.NoError(t, c.EncryptCookie(cookie))
+	assert.True(t, IsEncrypted(cookie.AuthToken))
+	assert.True(t, IsEncrypted(cookie.Ct0))
+	assert.NotContains(t, cookie.AuthToken, "token")
+
+	// a new cipher with the same passphrase reads what another one wrote
+	other, err := NewCipher("passphrase")
+	require.NoError(t, err)
+	require.NoError(t, other.DecryptCookie(cookie))
+	assert.Equal(t, &Cookie{AuthToken: "token", Ct0: "csrf"}, cookie)
+}
+
+func TestCipherWrongPassphrase(t *testing.T) {
+	c, err := NewCipher("passphrase")
+	require.NoError(t, err)
+	encrypted, err := c.Encrypt("token")
+	require.NoError(t, err)
+
+	other, err := NewCipher("other")
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+}
+
+func TestCipherKeepsPlainAndEncryptedValues(t *testing.T) {
+	c, err := NewCipher("passphrase")
+	require.NoError(t, err)
+
+	plain, err := c.Decrypt("token")
+	require.NoError(t, err)
+	assert.Equal(t, "token", plain)
+
+	empty, err := c.Encrypt("")
+	require.NoError(t, err)
+	assert.Equal(t, "", empty)
+
+	encrypted, err := c.Encrypt("token")
+	require.NoError(t, err)
+	again, err := c.Encrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, again)
+}
+
+func TestNewCipherEmptyPassphrase(t *testing.T) {
+	_, err := NewCipher("")
+
+	assert.Error(t, err)
+}
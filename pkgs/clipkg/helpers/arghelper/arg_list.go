@@ -27,3 +27,25 @@ func (l *TwitterListIdsArg) Set(str string) error {
 func (l *TwitterListIdsArg) String() string {
 	return fmt.Sprintf("%v", *l)
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+type CommunityIdsArg []uint64
+
+func (l *CommunityIdsArg) Set(str string) error {
+	vals := strings.Split(str, ",")
+
+	for _, v := range vals {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid community ID: %s", v)
+		}
+		*l = append(*l, id)
+	}
+
+	return nil
+}
+
+func (l *CommunityIdsArg) String() string {
+	return fmt.Sprintf("%v", *l)
+}
@@ -0,0 +1,25 @@
+package arghelper
+
+import (
+	"fmt"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SearchQueriesArg is not split by commas like the other args, since a comma
+// may be part of a query
+type SearchQueriesArg []string
+
+func (l *SearchQueriesArg) Set(str string) error {
+	query := strings.TrimSpace(str)
+	if query == "" {
+		return fmt.Errorf("empty search query")
+	}
+	*l = append(*l, query)
+	return nil
+}
+
+func (l *SearchQueriesArg) String() string {
+	return fmt.Sprintf("%q", *l)
+}
@@ -0,0 +1,131 @@
+package arghelper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// memberFilter narrows the members of a list or a following set down to the
+// include/exclude options of a subscription
+type memberFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+func newMemberFilter(include string, exclude string) *memberFilter {
+	if strings.TrimSpace(include) == "" && strings.TrimSpace(exclude) == "" {
+		return nil
+	}
+	return &memberFilter{
+		include: splitFilterKeys(include),
+		exclude: splitFilterKeys(exclude),
+	}
+}
+
+func splitFilterKeys(str string) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, v := range strings.Split(str, ",") {
+		v = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "@"))
+		if v != "" {
+			keys[v] = struct{}{}
+		}
+	}
+	return keys
+}
+
+func (f *memberFilter) match(keys map[string]struct{}, user *twitterclient.User) bool {
+	if _, ok := keys[strconv.FormatUint(user.TwitterId, 10)]; ok {
+		return true
+	}
+	_, ok := keys[strings.ToLower(user.ScreenName)]
+	return ok
+}
+
+func (f *memberFilter) Apply(users []*twitterclient.User) []*twitterclient.User {
+	if f == nil {
+		return users
+	}
+	res := make([]*twitterclient.User, 0, len(users))
+	for _, user := range users {
+		if len(f.include) != 0 && !f.match(f.include, user) {
+			continue
+		}
+		if f.match(f.exclude, user) {
+			continue
+		}
+		res = append(res, user)
+	}
+	return res
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AddSubscriptions appends the targets of the given subscriptions to the
+// command line arguments, skipping targets that are already present
+func (h *helper) AddSubscriptions(subs []*model.Subscription) error {
+	for _, sub := range subs {
+		if !sub.Enabled {
+			continue
+		}
+
+		switch sub.Kind {
+		case model.SUBSCRIPTION_KIND_USER_NAME:
+			screenName := strings.TrimPrefix(sub.Target, "@")
+			if !containsScreenName(h.userTwitterScreenNamesArg, screenName) {
+				h.userTwitterScreenNamesArg = append(h.userTwitterScreenNamesArg, screenName)
+			}
+			continue
+		case model.SUBSCRIPTION_KIND_USER, model.SUBSCRIPTION_KIND_LIST, model.SUBSCRIPTION_KIND_FOLL:
+		default:
+			return fmt.Errorf("subscription %d has unknown kind: %s", sub.Id, sub.Kind)
+		}
+
+		id, err := strconv.ParseUint(sub.Target, 10, 64)
+		if err != nil {
+			return fmt.Errorf("subscription %d has invalid target: %s", sub.Id, sub.Target)
+		}
+
+		switch sub.Kind {
+		case model.SUBSCRIPTION_KIND_USER:
+			if !containsId(h.userTwitterIdsArg, id) {
+				h.userTwitterIdsArg = append(h.userTwitterIdsArg, id)
+			}
+		case model.SUBSCRIPTION_KIND_LIST:
+			if !containsId(h.twitterListIdsArg, id) {
+				h.twitterListIdsArg = append(h.twitterListIdsArg, id)
+			}
+			h.listFilters[id] = newMemberFilter(sub.Include, sub.Exclude)
+		case model.SUBSCRIPTION_KIND_FOLL:
+			if !containsId(h.userTwitterIdsForFollowersArg, id) {
+				h.userTwitterIdsForFollowersArg = append(h.userTwitterIdsForFollowersArg, id)
+			}
+			h.follFilters[id] = newMemberFilter(sub.Include, sub.Exclude)
+		}
+	}
+
+	return nil
+}
+
+func containsId(ids []uint64, id uint64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsScreenName(screenNames []string, screenName string) bool {
+	for _, v := range screenNames {
+		if strings.EqualFold(v, screenName) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package arghelper
+
+import (
+	"testing"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberFilter(t *testing.T) {
+	users := []*twitterclient.User{
+		{TwitterId: 1, ScreenName: "Alice"},
+		{TwitterId: 2, ScreenName: "bob"},
+		{TwitterId: 3, ScreenName: "carol"},
+	}
+
+	tests := []struct {
+		name     string
+		include  string
+		exclude  string
+		expected []uint64
+	}{
+		{"no filter", "", "", []uint64{1, 2, 3}},
+		{"include by screen name", "@alice,carol", "", []uint64{1, 3}},
+		{"exclude by id", "", "2", []uint64{1, 3}},
+		{"include and exclude", "alice, bob", "bob", []uint64{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res := newMemberFilter(test.include, test.exclude).Apply(users)
+			ids := make([]uint64, 0, len(res))
+			for _, user := range res {
+				ids = append(ids, user.TwitterId)
+			}
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+}
+
+func TestAddSubscriptions(t *testing.T) {
+	h := New(nil, UserTwitterIdsArg{10}, nil, nil, nil)
+
+	err := h.AddSubscriptions([]*model.Subscription{
+		{Kind: model.SUBSCRIPTION_KIND_USER, Target: "10", Enabled: true},
+		{Kind: model.SUBSCRIPTION_KIND_USER_NAME, Target: "@alice", Enabled: true},
+		{Kind: model.SUBSCRIPTION_KIND_LIST, Target: "20", Exclude: "bob", Enabled: true},
+		{Kind: model.SUBSCRIPTION_KIND_FOLL, Target: "30", Enabled: false},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, UserTwitterIdsArg{10}, h.userTwitterIdsArg)
+	assert.Equal(t, UserTwitterScreenNamesArg{"alice"}, h.userTwitterScreenNamesArg)
+	assert.Equal(t, TwitterListIdsArg{20}, h.twitterListIdsArg)
+	assert.Empty(t, h.userTwitterIdsForFollowersArg)
+	assert.NotNil(t, h.listFilters[20])
+
+	err = h.AddSubscriptions([]*model.Subscription{
+		{Id: 7, Kind: model.SUBSCRIPTION_KIND_LIST, Target: "abc", Enabled: true},
+	})
+	assert.Error(t, err)
+}
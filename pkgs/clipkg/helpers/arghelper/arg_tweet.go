@@ -0,0 +1,47 @@
+package arghelper
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+var tweetHosts = map[string]bool{
+	"x.com":              true,
+	"twitter.com":        true,
+	"mobile.x.com":       true,
+	"mobile.twitter.com": true,
+}
+
+// ParseTweetId returns the id of a tweet given as is or by its URL, such as
+// https://x.com/user/status/1 or https://twitter.com/i/web/status/1
+func ParseTweetId(str string) (uint64, error) {
+	str = strings.TrimSpace(str)
+	if id, err := strconv.ParseUint(str, 10, 64); err == nil && id != 0 {
+		return id, nil
+	}
+
+	rawUrl := str
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "https://" + rawUrl
+	}
+	u, err := url.Parse(rawUrl)
+	if err != nil || !tweetHosts[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")] {
+		return 0, fmt.Errorf("invalid tweet ID or URL: %s", str)
+	}
+
+	// the photo or video of the tweet may follow its id
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] != "status" && parts[i] != "statuses" {
+			continue
+		}
+		if id, err := strconv.ParseUint(parts[i+1], 10, 64); err == nil && id != 0 {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid tweet ID or URL: %s", str)
+}
@@ -0,0 +1,33 @@
+package arghelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTweetId(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected uint64
+	}{
+		{"1790000000000000301", 1790000000000000301},
+		{"https://x.com/fake_artist/status/1790000000000000301", 1790000000000000301},
+		{"https://twitter.com/fake_artist/status/1790000000000000301/photo/2?s=20", 1790000000000000301},
+		{"mobile.twitter.com/i/web/status/1790000000000000301", 1790000000000000301},
+		{"https://www.x.com/fake_artist/statuses/1790000000000000301", 1790000000000000301},
+	}
+	for _, test := range tests {
+		t.Run(test.arg, func(t *testing.T) {
+			id, err := ParseTweetId(test.arg)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, id)
+		})
+	}
+
+	for _, arg := range []string{"", "0", "fake_artist", "https://example.com/fake_artist/status/1", "https://x.com/fake_artist", "https://x.com/fake_artist/status/abc"} {
+		_, err := ParseTweetId(arg)
+		assert.Error(t, err, arg)
+	}
+}
@@ -15,6 +15,14 @@ type helper struct {
 	userTwitterScreenNamesArg     UserTwitterScreenNamesArg
 	twitterListIdsArg             TwitterListIdsArg
 	userTwitterIdsForFollowersArg UserTwitterIdsArg
+
+	// followerMembersArg are the users whose followers are downloaded, unlike
+	// userTwitterIdsForFollowersArg whose followings are
+	followerMembersArg UserTwitterIdsArg
+	myLists            bool
+
+	listFilters map[uint64]*memberFilter
+	follFilters map[uint64]*memberFilter
 }
 
 func New(
@@ -30,6 +38,8 @@ func New(
 		userTwitterScreenNamesArg:     userTwitterScreenNamesArg,
 		twitterListIdsArg:             twitterListIdsArg,
 		userTwitterIdsForFollowersArg: userTwitterIdsForFollowersArg,
+		listFilters:                   make(map[uint64]*memberFilter),
+		follFilters:                   make(map[uint64]*memberFilter),
 	}
 }
 
@@ -82,6 +92,7 @@ func (h *helper) GetTitledUserLists(
 				logger.Errorln("failed to get all list members:", err)
 				continue
 			}
+			members = h.listFilters[listId].Apply(members)
 			titledUserList, err := twitterclient.NewTulByRawListByteAndMembers(gjson, members)
 			if err != nil {
 				logger.Errorln("failed to create TitledUserList by listId:", err)
@@ -103,6 +114,7 @@ func (h *helper) GetTitledUserLists(
 				logger.Errorln("failed to get all following members:", err)
 				continue
 			}
+			followers = h.follFilters[userId].Apply(followers)
 			titledUserLists = append(
 				titledUserLists,
 				*twitterclient.NewTulByUserAndFollowers(user, followers),
@@ -110,5 +122,95 @@ func (h *helper) GetTitledUserLists(
 		}
 	}
 
+	for _, userId := range h.followerMembersArg {
+		user, err := h.client.GetUserById(ctx, userId)
+		if err != nil {
+			logger.Errorln("failed to get user by id for follower members:", err)
+			continue
+		}
+		followers, err := h.client.ListAllFollowerMembersByUserId(ctx, userId)
+		if err != nil {
+			logger.Errorln("failed to get all follower members:", err)
+			continue
+		}
+		// unlike followings, followers are saved into a list folder of their own
+		titledUserLists = append(
+			titledUserLists,
+			*twitterclient.NewPseudoTul(twitterclient.TITLED_TYPE_FOLLOWER_MEMBERS, user.TwitterId, user.Title()+" followers", followers),
+		)
+	}
+
+	if h.myLists {
+		titledUserLists = append(titledUserLists, h.getMyTitledUserLists(ctx)...)
+	}
+
 	return titledUserLists
 }
+
+// getMyTitledUserLists returns the lists owned or subscribed by the signed in
+// account, except those given by list_id already
+func (h *helper) getMyTitledUserLists(ctx context.Context) []twitterclient.TitledUserList {
+	logger := log.WithField("function", "getMyTitledUserLists")
+
+	screenName, err := h.client.GetScreenName(ctx)
+	if err != nil {
+		logger.Errorln("failed to get screen name of the account:", err)
+		return nil
+	}
+	me, err := h.client.GetUserByScreenName(ctx, screenName)
+	if err != nil {
+		logger.Errorln("failed to get user of the account:", err)
+		return nil
+	}
+
+	owned, err := h.client.ListOwnerships(ctx, me.TwitterId)
+	if err != nil {
+		logger.Errorln("failed to get owned lists:", err)
+	}
+	subscribed, err := h.client.ListSubscriptions(ctx, me.TwitterId)
+	if err != nil {
+		logger.Errorln("failed to get subscribed lists:", err)
+	}
+
+	titledUserLists := make([]twitterclient.TitledUserList, 0)
+	seen := make(map[uint64]bool)
+	for _, gjson := range append(owned, subscribed...) {
+		listId := gjson.Get("id_str").Uint()
+		if seen[listId] || containsId(h.twitterListIdsArg, listId) {
+			continue
+		}
+		seen[listId] = true
+
+		members, err := h.client.ListAllMembersByListId(ctx, listId)
+		if err != nil {
+			logger.Errorln("failed to get all list members:", err)
+			continue
+		}
+		members = h.listFilters[listId].Apply(members)
+		titledUserList, err := twitterclient.NewTulByRawListByteAndMembers(gjson, members)
+		if err != nil {
+			logger.Errorln("failed to create TitledUserList by listId:", err)
+			continue
+		}
+		titledUserLists = append(titledUserLists, *titledUserList)
+	}
+	logger.Infof("%d lists owned or subscribed by %s", len(titledUserLists), screenName)
+
+	return titledUserLists
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AddFollowerMembers adds the users whose followers are downloaded
+func (h *helper) AddFollowerMembers(userIds UserTwitterIdsArg) {
+	for _, id := range userIds {
+		if !containsId(h.followerMembersArg, id) {
+			h.followerMembersArg = append(h.followerMembersArg, id)
+		}
+	}
+}
+
+// AddMyLists adds every list owned or subscribed by the signed in account
+func (h *helper) AddMyLists() {
+	h.myLists = true
+}
@@ -10,9 +10,13 @@ import (
 type TwitterClient interface {
 	GetUserById(ctx context.Context, userId uint64) (*twitterclient.User, error)
 	GetUserByScreenName(ctx context.Context, screenName string) (*twitterclient.User, error)
+	GetScreenName(ctx context.Context) (string, error)
 
 	GetRawListByteById(ctx context.Context, listId uint64) (*gjson.Result, error)
 	ListAllMembersByListId(ctx context.Context, listId uint64) ([]*twitterclient.User, error)
+	ListOwnerships(ctx context.Context, userId uint64) ([]*gjson.Result, error)
+	ListSubscriptions(ctx context.Context, userId uint64) ([]*gjson.Result, error)
 
 	ListAllFollowingMembersByUserId(ctx context.Context, userId uint64) ([]*twitterclient.User, error)
+	ListAllFollowerMembersByUserId(ctx context.Context, userId uint64) ([]*twitterclient.User, error)
 }
@@ -1,12 +1,15 @@
 package metahelper
 
 import (
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/linkrepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/listentityrepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/listrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/mediarepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/previousnamerepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/userentityrepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/userrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/usersnapshotrepo"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -18,6 +21,12 @@ type helper struct {
 	listRepo         ListRepo
 	listEntityRepo   ListEntityRepo
 	previousNameRepo PreviousNameRepo
+	userSnapshotRepo UserSnapshotRepo
+	mediaRepo        MediaRepo
+
+	// previousSnapshots are the latest snapshots of the users before this run,
+	// nil for a new user, which tell the profile media that did not change
+	previousSnapshots map[uint64]*model.UserSnapshot
 
 	twitterClientManager ClientManager
 }
@@ -31,6 +40,9 @@ func New(db *sqlx.DB, twitterClientManager ClientManager) *helper {
 		listRepo:             listrepo.New(),
 		listEntityRepo:       listentityrepo.New(),
 		previousNameRepo:     previousnamerepo.New(),
+		userSnapshotRepo:     usersnapshotrepo.New(),
+		mediaRepo:            mediarepo.New(),
 		twitterClientManager: twitterClientManager,
+		previousSnapshots:    make(map[uint64]*model.UserSnapshot),
 	}
 }
@@ -28,13 +28,24 @@ type ListRepo interface {
 
 type ListEntityRepo interface {
 	Upsert(ctx context.Context, db *sqlx.DB, entity *model.ListEntity) error
-	UpdateStorageSavedByTwitterId(ctx context.Context, db *sqlx.DB, twitterId uint64, saved bool) error
+	UpdateStorageSavedByTwitterId(ctx context.Context, db *sqlx.DB, kind string, twitterId uint64, saved bool) error
 }
 
 type PreviousNameRepo interface {
 	Create(ctx context.Context, db *sqlx.DB, uid uint64, name string, screenName string) error
 }
 
+type UserSnapshotRepo interface {
+	Create(ctx context.Context, db *sqlx.DB, snapshot *model.UserSnapshot) error
+	GetLatestByUid(ctx context.Context, db *sqlx.DB, uid uint64) (*model.UserSnapshot, error)
+}
+
+type MediaRepo interface {
+	Create(ctx context.Context, db *sqlx.DB, media *model.Media) error
+	GetByUserIdAndHash(ctx context.Context, db *sqlx.DB, userId uint64, kind string, hash string) (*model.Media, error)
+	GetByUserIdAndKind(ctx context.Context, db *sqlx.DB, userId uint64, kind string) ([]*model.Media, error)
+}
+
 type ClientManager interface {
 	GetMasterClient() *twitterclient.Client
 }
@@ -0,0 +1,105 @@
+package metahelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_PROFILE_MEDIA_EXT = ".jpg"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// saveProfileMediaToStorage archives the current avatar and banner of a user into its folder
+func (h *helper) saveProfileMediaToStorage(ctx context.Context, userDir string, user *twitterclient.User) {
+	logger := log.WithFields(log.Fields{
+		"function": "saveProfileMediaToStorage",
+		"user":     user.ScreenName,
+	})
+
+	previous := h.previousSnapshots[user.TwitterId]
+	profileMedias := []struct {
+		kind      string
+		url       string
+		unchanged bool
+	}{
+		{model.MEDIA_KIND_AVATAR, user.OriginalProfileImageUrl(), previous != nil && previous.AvatarUrl == user.ProfileImageUrl},
+		{model.MEDIA_KIND_BANNER, user.OriginalProfileBannerUrl(), previous != nil && previous.BannerUrl == user.ProfileBannerUrl},
+	}
+
+	for _, pm := range profileMedias {
+		if pm.url == "" {
+			continue
+		}
+		if pm.unchanged && h.hasProfileMedia(ctx, user.TwitterId, pm.kind) {
+			logger.WithField("kind", pm.kind).Debugln("profile media url unchanged, skipping")
+			continue
+		}
+		if err := h.saveProfileMedia(ctx, userDir, user.TwitterId, pm.kind, pm.url); err != nil {
+			logger.WithField("kind", pm.kind).Warnln("failed to save profile media:", err)
+		}
+	}
+}
+
+// hasProfileMedia tells whether a profile media of the kind was archived, a
+// failed download is tried again even when the url did not change
+func (h *helper) hasProfileMedia(ctx context.Context, uid uint64, kind string) bool {
+	medias, err := h.mediaRepo.GetByUserIdAndKind(ctx, h.db, uid, kind)
+	return err == nil && len(medias) > 0
+}
+
+func (h *helper) saveProfileMedia(ctx context.Context, userDir string, uid uint64, kind string, url string) error {
+	client := h.twitterClientManager.GetMasterClient()
+	if client == nil {
+		return fmt.Errorf("no client available")
+	}
+
+	data, err := client.GetMediaBytesByUrl(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	existing, err := h.mediaRepo.GetByUserIdAndHash(ctx, h.db, uid, kind, hash)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		log.WithField("location", existing.Location).Debugln("profile media unchanged, skipping")
+		return nil
+	}
+
+	ext, err := utils.GetExtFromUrl(url)
+	if err != nil || ext == "" {
+		ext = DEFAULT_PROFILE_MEDIA_EXT
+	}
+	fileName := fmt.Sprintf("%s_%s%s", kind, time.Now().Format("20060102"), ext)
+	location, err := utils.UniquePath(filepath.Join(userDir, fileName))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(location, data, 0644); err != nil {
+		return err
+	}
+
+	return h.mediaRepo.Create(ctx, h.db, &model.Media{
+		UserId:   uid,
+		Location: location,
+		Kind:     kind,
+		Hash:     hash,
+	})
+}
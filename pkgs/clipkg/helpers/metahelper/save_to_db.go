@@ -2,6 +2,7 @@ package metahelper
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
@@ -27,7 +28,10 @@ func (h *helper) SaveToDb(ctx context.Context, metas []twitterclient.TitledUserL
 					return err
 				}
 			}
-		case twitterclient.TITLED_TYPE_TWITTER_FOLLOWERS:
+		case twitterclient.TITLED_TYPE_TWITTER_FOLLOWERS,
+			twitterclient.TITLED_TYPE_SEARCH,
+			twitterclient.TITLED_TYPE_COMMUNITY,
+			twitterclient.TITLED_TYPE_FOLLOWER_MEMBERS:
 			for _, user := range meta.Users {
 				if err := h.saveUserToDb(ctx, user); err != nil {
 					return err
@@ -77,9 +81,50 @@ func (h *helper) saveUserToDb(ctx context.Context, user *twitterclient.User) err
 		return err
 	}
 
+	if err := h.saveUserSnapshotToDb(ctx, user); err != nil {
+		logger.Errorln("failed to save user snapshot:", err)
+		return err
+	}
+
 	return nil
 }
 
+// saveUserSnapshotToDb records the current profile only when it differs from the latest snapshot
+func (h *helper) saveUserSnapshotToDb(ctx context.Context, user *twitterclient.User) error {
+	snapshot := &model.UserSnapshot{
+		Uid:            user.TwitterId,
+		ScreenName:     user.ScreenName,
+		Name:           user.Name,
+		Description:    user.Description,
+		Location:       user.Location,
+		Url:            user.Url,
+		AvatarUrl:      user.ProfileImageUrl,
+		BannerUrl:      user.ProfileBannerUrl,
+		FollowersCount: user.FollowersCount,
+		FriendsCount:   user.FriendsCount,
+		MediaCount:     user.MediaCount,
+		IsVerified:     user.IsVerified,
+		AccountCreatedAt: sql.NullTime{
+			Time:  user.CreatedAt,
+			Valid: !user.CreatedAt.IsZero(),
+		},
+	}
+
+	latest, err := h.userSnapshotRepo.GetLatestByUid(ctx, h.db, user.TwitterId)
+	if err != nil {
+		return err
+	}
+	// a user met twice in a run keeps the snapshot from before the run
+	if _, ok := h.previousSnapshots[user.TwitterId]; !ok {
+		h.previousSnapshots[user.TwitterId] = latest
+	}
+	if latest != nil && len(snapshot.Diff(latest)) == 0 {
+		return nil
+	}
+
+	return h.userSnapshotRepo.Create(ctx, h.db, snapshot)
+}
+
 func (h *helper) saveListToDb(ctx context.Context, list *twitterclient.TitledUserList) error {
 	logger := log.WithField("function", "SaveListToDb")
 
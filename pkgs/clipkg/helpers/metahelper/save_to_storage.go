@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
@@ -18,15 +19,19 @@ func (h *helper) SaveToStorage(ctx context.Context, rootDir string, metas []twit
 			if err := h.saveUserToStorage(ctx, rootDir, meta.BelongsTo); err != nil {
 				return err
 			}
-		case twitterclient.TITLED_TYPE_TWITTER_LIST:
-			if err := h.saveListToStorage(ctx, rootDir, &meta); err != nil {
-				return err
-			}
+		case twitterclient.TITLED_TYPE_TWITTER_LIST,
+			twitterclient.TITLED_TYPE_SEARCH,
+			twitterclient.TITLED_TYPE_COMMUNITY,
+			twitterclient.TITLED_TYPE_FOLLOWER_MEMBERS:
+			// the folders of the members are linked from the list folder
 			for _, user := range meta.Users {
 				if err := h.saveUserToStorage(ctx, rootDir, user); err != nil {
 					return err
 				}
 			}
+			if err := h.saveListToStorage(ctx, rootDir, &meta); err != nil {
+				return err
+			}
 		case twitterclient.TITLED_TYPE_TWITTER_FOLLOWERS:
 			for _, user := range meta.Users {
 				if err := h.saveUserToStorage(ctx, rootDir, user); err != nil {
@@ -81,6 +86,9 @@ func (h *helper) saveUserToStorage(ctx context.Context, rootDir string, user *tw
 		return err
 	}
 
+	// profile media are best effort, a failed download must not block the sync
+	h.saveProfileMediaToStorage(ctx, path, user)
+
 	return nil
 }
 
@@ -95,6 +103,7 @@ func (h *helper) saveListToStorage(ctx context.Context, rootDir string, list *tw
 	folderName := utils.ToLegalWindowsFileName(list.TwitterName)
 	record := &model.ListEntity{
 		LstId:      int64(list.Id), // TODO:
+		Kind:       list.Type,
 		Name:       list.TwitterName,
 		ParentDir:  rootDir,
 		FolderName: folderName,
@@ -115,24 +124,59 @@ func (h *helper) saveListToStorage(ctx context.Context, rootDir string, list *tw
 		return err
 	}
 
-	if err := h.listEntityRepo.UpdateStorageSavedByTwitterId(ctx, h.db, list.Id, true); err != nil {
+	if err := h.listEntityRepo.UpdateStorageSavedByTwitterId(ctx, h.db, list.Type, list.Id, true); err != nil {
 		logger.Errorln("failed to update list entity storage saved:", err)
 		return err
 	}
 
-	// TODO:
+	// the parent dir of the record is absolute, like the one of user entities
+	listDir := filepath.Join(record.ParentDir, record.FolderName)
 	for _, user := range list.Users {
-		h.linkRepo.Upsert(
-			ctx,
-			h.db,
-			&model.UserLink{
-				UserTwitterId:        user.TwitterId,
-				Name:                 utils.ToLegalWindowsFileName(user.Title()),
-				ListEntityIdBelongTo: record.Id.Int32,
-				StorageSaved:         false,
-			},
-		)
+		if user == nil {
+			continue
+		}
+		if err := h.saveUserLinkToStorage(ctx, listDir, record.Id.Int32, user); err != nil {
+			logger.Errorln("failed to link user into list directory:", err)
+			return err
+		}
 	}
 
 	return nil
 }
+
+// saveUserLinkToStorage links the folder of a member into the folder of its
+// list. A failed link is left unsaved, the next sync makes it again.
+func (h *helper) saveUserLinkToStorage(ctx context.Context, listDir string, listEntityId int32, user *twitterclient.User) error {
+	logger := log.WithField("function", "SaveUserLinkToStorage")
+
+	userEntity, err := h.userEntityRepo.GetByTwitterId(ctx, h.db, user.TwitterId)
+	if err != nil {
+		return err
+	}
+	if userEntity == nil {
+		logger.Warnln("user entity not found for Twitter ID", user.TwitterId)
+		return nil
+	}
+
+	lnk := &model.UserLink{
+		UserTwitterId:        user.TwitterId,
+		Name:                 userEntity.FolderName,
+		ListEntityIdBelongTo: listEntityId,
+	}
+	linkPath := filepath.Join(listDir, lnk.Name)
+	if _, err := os.Lstat(linkPath); err == nil {
+		lnk.StorageSaved = true
+	} else {
+		target, err := filepath.Rel(listDir, userEntity.Path())
+		if err != nil {
+			target = userEntity.Path()
+		}
+		if err := os.Symlink(target, linkPath); err != nil {
+			logger.Warnf("failed to link %s: %v", userEntity.Path(), err)
+		} else {
+			lnk.StorageSaved = true
+		}
+	}
+
+	return h.linkRepo.Upsert(ctx, h.db, lnk)
+}
@@ -0,0 +1,282 @@
+package synchelper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/helpers/metahelper"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/communityrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/searchrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/WangWilly/xSync/pkgs/downloading"
+	"github.com/WangWilly/xSync/pkgs/downloading/dtos/dldto"
+	"github.com/WangWilly/xSync/pkgs/downloading/dtos/smartpathdto"
+	"github.com/WangWilly/xSync/pkgs/downloading/heaphelper"
+	"github.com/WangWilly/xSync/pkgs/downloading/resolveworker"
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	UsersAssetsPath string
+	DumpPath        string
+	Downloading     downloading.Config
+	AutoFollow      bool
+}
+
+// helper runs the whole download pipeline for a set of titled user lists. It
+// keeps the failed tweets in memory between runs so a long lived process can
+// sync many times and only dump on exit.
+type helper struct {
+	cfg Config
+
+	db      *sqlx.DB
+	manager *twitterclient.Manager
+	dumper  *downloading.TweetDumper
+}
+
+func New(db *sqlx.DB, manager *twitterclient.Manager, cfg Config) (*helper, error) {
+	dumper := downloading.NewDumper(db)
+	if err := dumper.Load(cfg.DumpPath); err != nil {
+		return nil, fmt.Errorf("failed to load previous tweets: %w", err)
+	}
+
+	return &helper{
+		cfg:     cfg,
+		db:      db,
+		manager: manager,
+		dumper:  dumper,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Sync saves the meta data of the lists then downloads every new tweet of
+// their members. Tweets that failed to download are kept for Retry.
+func (h *helper) Sync(ctx context.Context, titledUserLists []twitterclient.TitledUserList) error {
+	logger := log.WithField("function", "Sync")
+
+	if len(titledUserLists) == 0 {
+		return nil
+	}
+
+	metaHelper := metahelper.New(h.db, h.manager)
+	if err := metaHelper.SaveToDb(ctx, titledUserLists); err != nil {
+		return fmt.Errorf("failed to save meta data to database: %w", err)
+	}
+	if err := metaHelper.SaveToStorage(ctx, h.cfg.UsersAssetsPath, titledUserLists); err != nil {
+		return fmt.Errorf("failed to save meta data to storage: %w", err)
+	}
+
+	if h.cfg.AutoFollow {
+		metaHelper.DoFollow(ctx, titledUserLists)
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	smartPaths := metaHelper.ToUserSmartPaths(ctx, titledUserLists)
+	heapHelper, err := heaphelper.New(titledUserLists, smartPaths)
+	if err != nil {
+		return err
+	}
+	dbWorker := resolveworker.NewDBWorker(h.db, h.manager, heapHelper)
+	downloadHelper := downloading.NewDownloadHelperWithConfig(h.cfg.Downloading, dbWorker)
+
+	toDump, err := downloadHelper.BatchUserDownloadWithDB(ctx)
+	if err != nil {
+		logger.Errorln("failed to download:", err)
+	}
+	for _, te := range toDump {
+		h.dumper.Push(te.GetUserSmartPath().Id(), te.GetTweet())
+	}
+
+	return nil
+}
+
+// Get saves the authors of the tweets like Sync does, then downloads the
+// tweets into the folders of their authors. The tweets saved before are left
+// out, and those that failed to download are kept for Retry.
+func (h *helper) Get(ctx context.Context, tweets []*twitterclient.Tweet) error {
+	titledUserLists := make([]twitterclient.TitledUserList, 0)
+	for _, author := range authorsOf(tweets) {
+		titledUserLists = append(titledUserLists, *twitterclient.NewTitledUserListByUser(author))
+	}
+	return h.get(ctx, titledUserLists, tweets)
+}
+
+// get is Get saving the authors of the tweets as titledUserLists
+func (h *helper) get(ctx context.Context, titledUserLists []twitterclient.TitledUserList, tweets []*twitterclient.Tweet) error {
+	logger := log.WithField("function", "get")
+
+	metaHelper := metahelper.New(h.db, h.manager)
+	if err := metaHelper.SaveToDb(ctx, titledUserLists); err != nil {
+		return fmt.Errorf("failed to save meta data to database: %w", err)
+	}
+	if err := metaHelper.SaveToStorage(ctx, h.cfg.UsersAssetsPath, titledUserLists); err != nil {
+		return fmt.Errorf("failed to save meta data to storage: %w", err)
+	}
+
+	////////////////////////////////////////////////////////////////////////////
+
+	smartPaths := make(map[uint64]*smartpathdto.UserSmartPath)
+	for _, smartPath := range metaHelper.ToUserSmartPaths(ctx, titledUserLists) {
+		smartPaths[smartPath.TwitterId()] = smartPath
+	}
+
+	// no heap is needed to download single tweets
+	dbWorker := resolveworker.NewDBWorker(h.db, h.manager, nil)
+	var toDownload []*dldto.NewEntity
+	for _, tweet := range tweets {
+		if tweet.Creator == nil {
+			logger.Warnf("skipping tweet %d without author", tweet.Id)
+			continue
+		}
+		smartPath, ok := smartPaths[tweet.Creator.TwitterId]
+		if !ok {
+			logger.Warnf("skipping tweet %d of ignored user %s", tweet.Id, tweet.Creator.Title())
+			continue
+		}
+		toDownload = append(toDownload, dbWorker.SaveNewTweetsWithDB(ctx, smartPath, []*twitterclient.Tweet{tweet})...)
+	}
+	logger.Infof("%d of %d tweets were not downloaded before", len(toDownload), len(tweets))
+
+	downloadHelper := downloading.NewDownloadHelperWithConfig(h.cfg.Downloading, dbWorker)
+	for _, te := range downloadHelper.BatchDownloadTweetWithDB(ctx, toDownload...) {
+		h.dumper.Push(te.GetUserSmartPath().Id(), te.GetTweet())
+	}
+
+	return nil
+}
+
+// authorsOf returns the authors of the tweets, each once
+func authorsOf(tweets []*twitterclient.Tweet) []*twitterclient.User {
+	res := make([]*twitterclient.User, 0)
+	seen := make(map[uint64]bool)
+	for _, tweet := range tweets {
+		if tweet.Creator == nil || seen[tweet.Creator.TwitterId] {
+			continue
+		}
+		seen[tweet.Creator.TwitterId] = true
+		res = append(res, tweet.Creator)
+	}
+	return res
+}
+
+// Search downloads the tweets found by a query of the search of x.com into the
+// folders of their authors, like Get does. The authors are saved as a list
+// named after the query, so its folder links their folders. Only the tweets
+// newer than those found by the last search of the query are fetched.
+func (h *helper) Search(ctx context.Context, query string) error {
+	logger := log.WithField("function", "Search").WithField("query", query)
+
+	search := &model.Search{Query: query, Name: query}
+	if err := searchrepo.New().Upsert(ctx, h.db, search); err != nil {
+		return fmt.Errorf("failed to save search %q: %w", query, err)
+	}
+
+	timeRange := utils.TimeRange{Begin: search.LatestReleaseTime.Time}
+	tweets, err := h.manager.GetMasterClient().SearchTweetsByTimeRange(ctx, query, timeRange)
+	if err != nil {
+		return fmt.Errorf("failed to search %q: %w", query, err)
+	}
+	logger.Infof("found %d new tweets", len(tweets))
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	authors := twitterclient.NewPseudoTul(twitterclient.TITLED_TYPE_SEARCH, uint64(search.Id), search.Name+" (search)", authorsOf(tweets))
+	if err := h.get(ctx, []twitterclient.TitledUserList{*authors}, tweets); err != nil {
+		return err
+	}
+
+	// the next search only fetches the tweets newer than these ones
+	latest := latestCreatedAt(search.LatestReleaseTime.Time, tweets)
+	if err := searchrepo.New().UpdateLatestReleaseTime(ctx, h.db, search.Id, latest); err != nil {
+		return fmt.Errorf("failed to update latest release time of search %q: %w", query, err)
+	}
+	return nil
+}
+
+// Community downloads the tweets posted in a community like Search does, into
+// the folders of their authors linked from the list folder of the community.
+// The recorded Spaces shared there are downloaded into the folders of their
+// hosts.
+func (h *helper) Community(ctx context.Context, communityId uint64) error {
+	logger := log.WithField("function", "Community").WithField("community_id", communityId)
+
+	client := h.manager.GetMasterClient()
+	found, err := client.GetCommunityById(ctx, communityId)
+	if err != nil {
+		return fmt.Errorf("failed to get community %d: %w", communityId, err)
+	}
+	community := &model.Community{Id: found.Id, Name: found.Name}
+	if err := communityrepo.New().Upsert(ctx, h.db, community); err != nil {
+		return fmt.Errorf("failed to save community %d: %w", communityId, err)
+	}
+
+	timeRange := utils.TimeRange{Begin: community.LatestReleaseTime.Time}
+	tweets, err := client.ListCommunityTweetsByTimeRange(ctx, communityId, timeRange)
+	if err != nil {
+		return fmt.Errorf("failed to list tweets of community %d: %w", communityId, err)
+	}
+	logger.Infof("found %d new tweets", len(tweets))
+	if len(tweets) == 0 {
+		return nil
+	}
+
+	authors := twitterclient.NewPseudoTul(twitterclient.TITLED_TYPE_COMMUNITY, community.Id, community.Name+" (community)", authorsOf(tweets))
+	if err := h.get(ctx, []twitterclient.TitledUserList{*authors}, tweets); err != nil {
+		return err
+	}
+
+	latest := latestCreatedAt(community.LatestReleaseTime.Time, tweets)
+	if err := communityrepo.New().UpdateLatestReleaseTime(ctx, h.db, community.Id, latest); err != nil {
+		return fmt.Errorf("failed to update latest release time of community %d: %w", communityId, err)
+	}
+	return nil
+}
+
+func latestCreatedAt(latest time.Time, tweets []*twitterclient.Tweet) time.Time {
+	for _, tweet := range tweets {
+		if tweet.CreatedAt.After(latest) {
+			latest = tweet.CreatedAt
+		}
+	}
+	return latest
+}
+
+// Retry downloads the failed tweets again, those failing twice are kept
+func (h *helper) Retry(ctx context.Context) error {
+	if h.dumper.Count() == 0 {
+		return nil
+	}
+
+	retrible, err := h.dumper.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list all failed tweets: %w", err)
+	}
+
+	// no heap is needed to download single tweets
+	dbWorker := resolveworker.NewDBWorker(h.db, h.manager, nil)
+	downloadHelper := downloading.NewDownloadHelperWithConfig(h.cfg.Downloading, dbWorker)
+
+	newFails := downloadHelper.BatchDownloadTweetWithDB(ctx, retrible...)
+	h.dumper.Clear()
+	for _, pt := range newFails {
+		h.dumper.Push(pt.Entity.Id(), pt.Tweet)
+	}
+
+	return nil
+}
+
+// Dump writes the failed tweets to disk so the next run can retry them
+func (h *helper) Dump() error {
+	return h.dumper.Dump(h.cfg.DumpPath)
+}
+
+func (h *helper) FailedCount() int {
+	return h.dumper.Count()
+}
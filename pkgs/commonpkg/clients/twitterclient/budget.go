@@ -0,0 +1,193 @@
+package twitterclient
+
+import (
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// BudgetLimits caps the requests of an account to an endpoint, 0 is no cap
+type BudgetLimits struct {
+	PerHour int
+	PerDay  int
+}
+
+// Budget is what an account may request on top of the rate limits reported by
+// Twitter. Endpoints override Default by the last part of their path, such as
+// UserMedia.
+type Budget struct {
+	Default   BudgetLimits
+	Endpoints map[string]BudgetLimits
+}
+
+// LimitsOf returns the caps of the endpoint at urlPath
+func (b *Budget) LimitsOf(urlPath string) BudgetLimits {
+	if b == nil {
+		return BudgetLimits{}
+	}
+	if limits, ok := b.Endpoints[path.Base(urlPath)]; ok {
+		return limits
+	}
+	return b.Default
+}
+
+// Budgets assigns a budget to every account the manager signs in
+type Budgets struct {
+	// Additional applies to the accounts other than the master without a
+	// budget of their own
+	Additional *Budget
+	// Accounts are budgets by screen name
+	Accounts map[string]*Budget
+}
+
+// BudgetOf returns the budget of an account, nil when it has none
+func (b *Budgets) BudgetOf(screenName string, master bool) *Budget {
+	if b == nil {
+		return nil
+	}
+	if budget, ok := b.Accounts[screenName]; ok {
+		return budget
+	}
+	if master {
+		return nil
+	}
+	return b.Additional
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// EndpointUsage counts the requests of an account to an endpoint in the
+// current hour and day
+type EndpointUsage struct {
+	Path      string    `json:"path"`
+	Hour      time.Time `json:"hour"`
+	HourCount int       `json:"hour_count"`
+	Day       time.Time `json:"day"`
+	DayCount  int       `json:"day_count"`
+}
+
+// At returns the counts as of now, which start over once their hour or day is
+// past
+func (u EndpointUsage) At(now time.Time) EndpointUsage {
+	u.roll(now)
+	return u
+}
+
+// roll starts the counts over once their hour or day is past
+func (u *EndpointUsage) roll(now time.Time) {
+	if hour := now.Truncate(time.Hour); !u.Hour.Equal(hour) {
+		u.Hour = hour
+		u.HourCount = 0
+	}
+	if day := startOfDay(now); !u.Day.Equal(day) {
+		u.Day = day
+		u.DayCount = 0
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// budgetTracker counts the requests of a client by endpoint and tells when its
+// budget is spent. Requests are counted even without a budget for the reports.
+type budgetTracker struct {
+	budget *Budget
+	usage  map[string]*EndpointUsage
+	mutex  sync.Mutex
+
+	// onUpdate is called with the counts after every request
+	onUpdate func(usage []EndpointUsage)
+}
+
+func newBudgetTracker(budget *Budget) *budgetTracker {
+	return &budgetTracker{
+		budget: budget,
+		usage:  make(map[string]*EndpointUsage),
+	}
+}
+
+func (t *budgetTracker) setBudget(budget *Budget) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.budget = budget
+}
+
+func (t *budgetTracker) getBudget() *Budget {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.budget
+}
+
+func (t *budgetTracker) count(path string, now time.Time) {
+	t.mutex.Lock()
+	usage, ok := t.usage[path]
+	if !ok {
+		usage = &EndpointUsage{Path: path}
+		t.usage[path] = usage
+	}
+	usage.roll(now)
+	usage.HourCount++
+	usage.DayCount++
+	onUpdate := t.onUpdate
+	t.mutex.Unlock()
+
+	if onUpdate != nil {
+		onUpdate(t.snapshot(now))
+	}
+}
+
+// exhaustedUntil returns when the budget of path is available again, the zero
+// time when it is not spent
+func (t *budgetTracker) exhaustedUntil(path string, now time.Time) time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	limits := t.budget.LimitsOf(path)
+	usage, ok := t.usage[path]
+	if !ok || (limits.PerHour == 0 && limits.PerDay == 0) {
+		return time.Time{}
+	}
+	usage.roll(now)
+
+	var until time.Time
+	if limits.PerDay > 0 && usage.DayCount >= limits.PerDay {
+		until = usage.Day.AddDate(0, 0, 1)
+	} else if limits.PerHour > 0 && usage.HourCount >= limits.PerHour {
+		until = usage.Hour.Add(time.Hour)
+	}
+	return until
+}
+
+// restore loads the counts saved by a previous run, the ones of a past hour or
+// day start over
+func (t *budgetTracker) restore(usage []EndpointUsage, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, item := range usage {
+		item := item
+		item.roll(now)
+		t.usage[item.Path] = &item
+	}
+}
+
+// snapshot returns the counts of every endpoint, sorted by path
+func (t *budgetTracker) snapshot(now time.Time) []EndpointUsage {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := make([]EndpointUsage, 0, len(t.usage))
+	for _, usage := range t.usage {
+		usage.roll(now)
+		res = append(res, *usage)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
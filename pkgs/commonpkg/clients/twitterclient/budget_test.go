@@ -0,0 +1,113 @@
+package twitterclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetTrackerCaps(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 30, 0, 0, time.Local)
+	tracker := newBudgetTracker(&Budget{
+		Default:   BudgetLimits{PerHour: 2, PerDay: 3},
+		Endpoints: map[string]BudgetLimits{"UserMedia": {PerHour: 1}},
+	})
+	const following = "/i/api/graphql/id/Following"
+	const media = "/i/api/graphql/id/UserMedia"
+
+	tracker.count(following, now)
+	assert.True(t, tracker.exhaustedUntil(following, now).IsZero())
+	tracker.count(following, now)
+	assert.Equal(t, time.Date(2025, 3, 1, 11, 0, 0, 0, time.Local), tracker.exhaustedUntil(following, now))
+
+	// the next hour starts over, but not the day
+	now = now.Add(time.Hour)
+	assert.True(t, tracker.exhaustedUntil(following, now).IsZero())
+	tracker.count(following, now)
+	assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.Local), tracker.exhaustedUntil(following, now))
+
+	// an endpoint override replaces the default caps
+	tracker.count(media, now)
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local), tracker.exhaustedUntil(media, now))
+
+	now = time.Date(2025, 3, 2, 0, 0, 1, 0, time.Local)
+	assert.True(t, tracker.exhaustedUntil(following, now).IsZero())
+	usage := tracker.snapshot(now)
+	require.Len(t, usage, 2)
+	assert.Equal(t, following, usage[0].Path)
+	assert.Zero(t, usage[0].DayCount)
+}
+
+func TestBudgetsBudgetOf(t *testing.T) {
+	own := &Budget{Default: BudgetLimits{PerDay: 10}}
+	additional := &Budget{Default: BudgetLimits{PerDay: 100}}
+	budgets := &Budgets{Additional: additional, Accounts: map[string]*Budget{"alice": own}}
+
+	assert.Same(t, own, budgets.BudgetOf("alice", true))
+	assert.Same(t, additional, budgets.BudgetOf("bob", false))
+	assert.Nil(t, budgets.BudgetOf("carol", true))
+	assert.Nil(t, (*Budgets)(nil).BudgetOf("bob", false))
+	assert.Equal(t, BudgetLimits{}, (*Budget)(nil).LimitsOf("/path"))
+}
+
+func TestManagerSkipsClientsOverBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	storePath := filepath.Join(t.TempDir(), "rate_limits.json")
+	store, err := NewFileRateLimitStore(storePath)
+	require.NoError(t, err)
+	SetRateLimitStore(store)
+	t.Cleanup(func() { SetRateLimitStore(nil) })
+
+	manager := NewManager()
+	manager.SetBudgets(&Budgets{Additional: &Budget{Default: BudgetLimits{PerHour: 2}}})
+	master := newSignedInClient("master", "master")
+	other := newSignedInClient("other", "other")
+	manager.SetMasterClient(master)
+	require.NoError(t, manager.AddClient(other))
+	require.NoError(t, manager.AddClient(master))
+
+	const path = "/i/api/graphql/id/UserMedia"
+	for i := 0; i < 2; i++ {
+		client := manager.TrySelectClient(path)
+		require.Same(t, other, client)
+		_, err := client.restyClient.R().Get(server.URL + path)
+		require.NoError(t, err)
+	}
+
+	// the additional account spent its hour, the master has no budget
+	assert.Same(t, master, manager.TrySelectClient(path))
+	until := manager.AvailableAt(other, path)
+	assert.True(t, time.Now().Truncate(time.Hour).Add(time.Hour).Equal(until), until)
+
+	usage := manager.GetUsage()
+	require.Len(t, usage, 2)
+	assert.Equal(t, "other", usage[0].ScreenName)
+	require.Len(t, usage[0].Usage, 1)
+	assert.Equal(t, 2, usage[0].Usage[0].HourCount)
+
+	// the counts survive a restart
+	require.NoError(t, store.Flush())
+	reopened, err := NewFileRateLimitStore(storePath)
+	require.NoError(t, err)
+	SetRateLimitStore(reopened)
+
+	restarted := NewManager()
+	restarted.SetBudgets(&Budgets{Additional: &Budget{Default: BudgetLimits{PerHour: 2}}})
+	again := newSignedInClient("other", "other")
+	require.NoError(t, restarted.AddClient(again))
+	assert.Nil(t, restarted.TrySelectClient(path))
+	at, ok := restarted.NextAvailableAt(path)
+	assert.True(t, ok)
+	assert.True(t, until.Equal(at), at)
+
+	// lifting the budget applies to the clients already signed in
+	restarted.SetBudgets(nil)
+	assert.Same(t, again, restarted.TrySelectClient(path))
+}
@@ -1,7 +1,10 @@
 package twitterclient
 
 import (
+	"net/url"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
@@ -13,6 +16,8 @@ import (
 const (
 	X_HOME = "https://x.com/home"
 	X_IMG  = "twimg.com"
+	// the recordings of the Spaces are under video.pscp.tv and the like
+	X_SPACES = "pscp.tv"
 )
 
 // header keys
@@ -36,10 +41,21 @@ const (
 
 type Client struct {
 	restyClient *resty.Client
+	authToken   string
 	screenName  string
 	rateLimiter *rateLimitManager
 	error       error
 	mutex       sync.RWMutex
+
+	// tooManyRequests counts the 429s in a row, quarantinedUntil keeps the
+	// client out of the rotation without an error
+	tooManyRequests  int
+	quarantinedUntil time.Time
+
+	// proxy and mediaProxy replace the proxy of the environment, the latter
+	// for the media CDN only
+	proxy      *url.URL
+	mediaProxy *url.URL
 }
 
 func New(authToken, ct0 string) *Client {
@@ -54,10 +70,24 @@ func New(authToken, ct0 string) *Client {
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// isMediaHost tells the hosts of the media from x.com, whose requests are not
+// counted against the rate limits of the account
+func isMediaHost(host string) bool {
+	return strings.HasSuffix(host, X_IMG) || strings.HasSuffix(host, X_SPACES)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
 func (c *Client) SetLogger(logger *log.Logger) {
 	c.restyClient.SetLogger(logger)
 }
 
+// AuthToken returns the auth_token cookie the client signs in with, which
+// identifies the account across reloads of the cookies
+func (c *Client) AuthToken() string {
+	return c.authToken
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Client State Management
 
@@ -78,9 +108,10 @@ func (c *Client) SetError(err error) {
 	}
 }
 
-// IsAvailable checks if the client is available for use
+// IsAvailable checks if the client is available for use, a quarantined client
+// is not until its quarantine ends
 func (c *Client) IsAvailable() bool {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
-	return c.error == nil
+	return c.error == nil && !time.Now().Before(c.quarantinedUntil)
 }
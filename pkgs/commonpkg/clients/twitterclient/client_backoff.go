@@ -0,0 +1,129 @@
+package twitterclient
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RETRY_COUNT    = 5
+	RETRY_MIN_WAIT = time.Second
+	// RETRY_MAX_WAIT caps the wait before a retry. A 429 resetting later than
+	// that fails right away, the caller is better off with another client.
+	RETRY_MAX_WAIT = time.Minute
+
+	// QUARANTINE_AFTER_429 is how many 429s in a row take a client out of
+	// the rotation for QUARANTINE_COOL_OFF, or until its reset if later
+	QUARANTINE_AFTER_429 = 3
+	QUARANTINE_COOL_OFF  = 15 * time.Minute
+	// POST_LIMIT_COOL_OFF is how long a client that reached the daily limit
+	// for seeing posts is left alone
+	POST_LIMIT_COOL_OFF = time.Hour
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// retryAfter is how long the response asks to wait, from Retry-After in
+// seconds or as a date, else from x-rate-limit-reset. It is 0 when neither is
+// set or they are already past.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0)
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0)
+		}
+	}
+	if value := header.Get("X-Rate-Limit-Reset"); value != "" {
+		if reset, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0)
+		}
+	}
+	return 0
+}
+
+// withJitter spreads the retries of the clients hitting the same reset, by up
+// to a quarter of the wait plus a second
+func withJitter(wait time.Duration) time.Duration {
+	return wait + rand.N(wait/4+time.Second)
+}
+
+// retryAfterFunc waits for the reset of a 429, other failures use the
+// exponential backoff with jitter of resty
+func (c *Client) retryAfterFunc(client *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if resp.StatusCode() != http.StatusTooManyRequests {
+		return 0, nil
+	}
+	wait := retryAfter(resp.Header(), time.Now())
+	if wait == 0 {
+		return 0, nil
+	}
+	if wait > RETRY_MAX_WAIT {
+		return 0, fmt.Errorf("rate limit resets in %s", wait.Round(time.Second))
+	}
+	return withJitter(wait), nil
+}
+
+// countTooManyRequests quarantines the client after QUARANTINE_AFTER_429
+// responses in a row were 429s, any other response starts the count over
+func (c *Client) countTooManyRequests(resp *resty.Response) {
+	if isMediaHost(resp.Request.RawRequest.URL.Hostname()) {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if resp.StatusCode() != http.StatusTooManyRequests {
+		c.tooManyRequests = 0
+		return
+	}
+	c.tooManyRequests++
+	if c.tooManyRequests < QUARANTINE_AFTER_429 {
+		return
+	}
+	c.tooManyRequests = 0
+
+	now := time.Now()
+	until := now.Add(max(QUARANTINE_COOL_OFF, retryAfter(resp.Header(), now)))
+	c.quarantine(until, fmt.Sprintf("%d responses in a row were 429", QUARANTINE_AFTER_429))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Quarantine takes the client out of the rotation until the given time, after
+// which it is available again on its own. An earlier time than the current
+// quarantine is ignored.
+func (c *Client) Quarantine(until time.Time, reason string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.quarantine(until, reason)
+}
+
+func (c *Client) quarantine(until time.Time, reason string) {
+	if !until.After(c.quarantinedUntil) {
+		return
+	}
+	c.quarantinedUntil = until
+	log.
+		WithFields(log.Fields{"client": c.screenName, "until": until.Format(time.TimeOnly)}).
+		Warnln("client is quarantined:", reason)
+}
+
+// QuarantinedUntil returns when the quarantine of the client ends, the zero
+// time when it is not quarantined
+func (c *Client) QuarantinedUntil() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if time.Now().Before(c.quarantinedUntil) {
+		return c.quarantinedUntil
+	}
+	return time.Time{}
+}
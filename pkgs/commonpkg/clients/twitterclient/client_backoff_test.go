@@ -0,0 +1,107 @@
+package twitterclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+	}{
+		{"none", nil, 0},
+		{"seconds", map[string]string{"Retry-After": "30"}, 30 * time.Second},
+		{"date", map[string]string{"Retry-After": now.Add(time.Minute).Format(http.TimeFormat)}, time.Minute},
+		{"past date", map[string]string{"Retry-After": now.Add(-time.Minute).Format(http.TimeFormat)}, 0},
+		{"reset", map[string]string{"X-Rate-Limit-Reset": strconv.FormatInt(now.Add(5*time.Minute).Unix(), 10)}, 5 * time.Minute},
+		{
+			"retry after first",
+			map[string]string{"Retry-After": "10", "X-Rate-Limit-Reset": strconv.FormatInt(now.Add(5*time.Minute).Unix(), 10)},
+			10 * time.Second,
+		},
+		{"garbage", map[string]string{"Retry-After": "soon", "X-Rate-Limit-Reset": "later"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tt.header {
+				header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, retryAfter(header, now))
+		})
+	}
+}
+
+func TestWithJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		got := withJitter(20 * time.Second)
+		assert.GreaterOrEqual(t, got, 20*time.Second)
+		assert.Less(t, got, 26*time.Second)
+	}
+}
+
+func TestClientQuarantineAfter429s(t *testing.T) {
+	status := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := newSignedInClient("token", "user")
+	get := func() {
+		client.restyClient.R().Get(server.URL + "/i/api/graphql/id/UserMedia")
+	}
+
+	// a success in between starts the count over
+	get()
+	get()
+	status = http.StatusOK
+	get()
+	status = http.StatusTooManyRequests
+	get()
+	get()
+	require.True(t, client.IsAvailable())
+
+	get()
+	assert.False(t, client.IsAvailable())
+	assert.NoError(t, client.GetError())
+	until := client.QuarantinedUntil()
+	assert.WithinDuration(t, time.Now().Add(QUARANTINE_COOL_OFF), until, time.Minute)
+	assert.Equal(t, until, client.AvailableAt(GRAPHQL_USER_MEDIA))
+}
+
+func TestManagerQuarantineRestores(t *testing.T) {
+	manager := NewManager()
+	first := newSignedInClient("first", "first")
+	second := newSignedInClient("second", "second")
+	require.NoError(t, manager.AddClient(first))
+	require.NoError(t, manager.AddClient(second))
+
+	until := time.Now().Add(50 * time.Millisecond)
+	manager.QuarantineClient(first, until, "reached the limit for seeing posts today")
+	// an earlier end does not shorten it
+	manager.QuarantineClient(first, time.Now(), "again")
+
+	assert.Equal(t, []*Client{second}, manager.GetAvailableClients())
+	assert.Same(t, second, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+
+	manager.QuarantineClient(second, until.Add(time.Hour), "too many requests")
+	assert.Nil(t, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+	at, ok := manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.True(t, ok)
+	assert.Equal(t, until, at)
+
+	time.Sleep(time.Until(until))
+	assert.True(t, first.IsAvailable())
+	assert.True(t, first.QuarantinedUntil().IsZero())
+	assert.Same(t, first, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+}
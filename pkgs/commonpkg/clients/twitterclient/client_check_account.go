@@ -0,0 +1,52 @@
+package twitterclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/tidwall/gjson"
+)
+
+// CheckAccount signs in and looks the signed in account up, which is refused
+// for locked and suspended accounts even though their cookies still sign in
+func (c *Client) CheckAccount(ctx context.Context) (string, error) {
+	screenName, err := c.GetScreenName(ctx)
+	if err != nil {
+		return "", err
+	}
+	if screenName == "" {
+		return "", ErrSignedOut
+	}
+
+	resp, err := c.restyClient.R().SetContext(ctx).Get(c.buildUserByScreenNameUrl(screenName))
+	if err != nil {
+		var httpErr *utils.HttpStatusError
+		if errors.As(err, &httpErr) && resp != nil {
+			if accountErr := checkAccountResp(resp.Body()); accountErr != nil {
+				return screenName, accountErr
+			}
+		}
+		return screenName, fmt.Errorf("failed to look up @%s: %w", screenName, err)
+	}
+	return screenName, checkAccountResp(resp.Body())
+}
+
+func checkAccountResp(body []byte) error {
+	var apiErr *TwitterApiError
+	if errors.As(CheckApiResp(body), &apiErr) {
+		switch apiErr.Code {
+		case ErrAccountLocked:
+			return ErrLockedAccount
+		case ErrAccountSuspended:
+			return ErrSuspendedAccount
+		}
+	}
+
+	result := gjson.GetBytes(body, "data.user.result")
+	if result.Get("__typename").String() == "UserUnavailable" && result.Get("reason").String() == "Suspended" {
+		return ErrSuspendedAccount
+	}
+	return nil
+}
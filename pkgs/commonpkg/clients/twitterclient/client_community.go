@@ -0,0 +1,118 @@
+package twitterclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/tidwall/gjson"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	COMMUNITY_VARIABLES_FORM        = `{"communityId":"%d"}`
+	COMMUNITY_TWEETS_VARIABLES_FORM = `{"communityId":"%d","count":%d,"cursor":"%s","displayLocation":"Community","rankingMode":"Recency","withCommunity":true}`
+	COMMUNITY_FEATURES              = `{"c9s_list_members_action_api_enabled":false,"c9s_superc9s_indication_enabled":false}`
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Community is an X Community, whose members post to its own timeline
+type Community struct {
+	Id   uint64
+	Name string
+}
+
+// GetCommunityById returns the community, an error if it does not exist
+func (c *Client) GetCommunityById(ctx context.Context, communityId uint64) (*Community, error) {
+	u, _ := url.Parse(API_HOST)
+	u = u.JoinPath(GraphQLPath(GRAPHQL_COMMUNITY_QUERY))
+
+	params := url.Values{}
+	params.Set("variables", fmt.Sprintf(COMMUNITY_VARIABLES_FORM, communityId))
+	params.Set("features", graphqlFeatures(GRAPHQL_COMMUNITY_QUERY, COMMUNITY_FEATURES))
+	u.RawQuery = params.Encode()
+
+	resp, err := c.restyClient.R().SetContext(ctx).Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	result := gjson.GetBytes(resp.Body(), "data.communityResults.result")
+	if result.Get("__typename").String() != "Community" {
+		return nil, fmt.Errorf("the community with ID %d doesn't exist", communityId)
+	}
+	return &Community{
+		Id:   result.Get("rest_id").Uint(),
+		Name: result.Get("name").String(),
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ListCommunityTweetsByTimeRange retrieves the tweets posted in the community
+// from the latest to the earliest within the time range
+func (c *Client) ListCommunityTweetsByTimeRange(ctx context.Context, communityId uint64, timeRange utils.TimeRange) ([]*Tweet, error) {
+	results := make([]*Tweet, 0)
+	cursor := ""
+
+	for {
+		currentTweets, next, err := c.communityTweetsPage(ctx, communityId, DEFAULT_COMMUNITY_PAGE_SIZE, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(currentTweets) == 0 || next == cursor {
+			break
+		}
+
+		cursor = next
+
+		cutMin, cutMax, currentTweets := filterTweetsByTimeRange(currentTweets, timeRange.Begin, timeRange.End)
+		inRange := len(currentTweets)
+		currentTweets, err = c.refetchUnavailableTweets(ctx, currentTweets)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, currentTweets...)
+
+		if cutMin {
+			break
+		}
+		if cutMax && inRange != 0 {
+			timeRange.End = time.Time{}
+		}
+	}
+
+	// the timeline tells the community of its tweets
+	for _, tweet := range results {
+		tweet.CommunityId = communityId
+	}
+	return results, nil
+}
+
+// communityTweetsPage returns a page of the latest tweets of the community,
+// where the tweets that cannot be seen are still to be fetched
+func (c *Client) communityTweetsPage(ctx context.Context, communityId uint64, pageSize int, cursor string) ([]*Tweet, string, error) {
+	listParams := ListParams{
+		VariablesForm: COMMUNITY_TWEETS_VARIABLES_FORM,
+		Features:      USER_FEATURES,
+
+		Id:     communityId,
+		Count:  pageSize,
+		Cursor: cursor,
+	}
+
+	items, next, err := c.getTimelineItemContents(ctx, GRAPHQL_COMMUNITY_TWEETS_TIMELINE, listParams, INST_PATH_COMMUNITY)
+	if err != nil {
+		return nil, "", err
+	}
+	tweets, err := itemContentsToTweets(items)
+	if err != nil {
+		return nil, "", err
+	}
+	return tweets, next, nil
+}
@@ -12,14 +12,30 @@ const (
 	GRAPHQL_USER_BY_SCREEN_NAME = "/i/api/graphql/xmU6X_CKVnQ5lSrCbAmJsg/UserByScreenName"
 	GRAPHQL_USER_MEDIA          = "/i/api/graphql/MOLbHrtk8Ovu7DUNOLcXiA/UserMedia"
 	GRAPHQL_FOLLOWING           = "/i/api/graphql/7FEKOPNAvxWASt6v9gfCXw/Following"
+	GRAPHQL_FOLLOWERS           = "/i/api/graphql/rRXFSG5vR6drKr5M37YOTw/Followers"
+	GRAPHQL_VERIFIED_FOLLOWERS  = "/i/api/graphql/6hgxYm5h3xw7RMjRnj4Nyw/BlueVerifiedFollowers"
 	GRAPHQL_LIKES               = "/i/api/graphql/aeJWz--kknVBOl7wQ7gh7Q/Likes"
 
+	// Tweet-related endpoints
+	GRAPHQL_TWEET_RESULT_BY_REST_ID = "/i/api/graphql/Xl5pC_lBk_gcO2ItU39DQw/TweetResultByRestId"
+	GRAPHQL_TWEET_DETAIL            = "/i/api/graphql/nBS-WpgA6ZG0CyNHD517JQ/TweetDetail"
+	GRAPHQL_SEARCH_TIMELINE         = "/i/api/graphql/UN1i3zUiCWa-6r-Uaho4fw/SearchTimeline"
+
+	// Community and Space endpoints
+	GRAPHQL_COMMUNITY_QUERY           = "/i/api/graphql/lUBKrilodgg9Nikaw3cIiA/CommunityQuery"
+	GRAPHQL_COMMUNITY_TWEETS_TIMELINE = "/i/api/graphql/mhwSsmub4JZgHcs0dtsjrw/CommunityTweetsTimeline"
+	GRAPHQL_AUDIO_SPACE_BY_ID         = "/i/api/graphql/xVEzTKg_mLTHubK5ayL0HA/AudioSpaceById"
+
 	// List-related endpoints
-	GRAPHQL_LIST_BY_REST_ID = "/i/api/graphql/ZMQOSpxDo0cP5Cdt8MgEVA/ListByRestId"
-	GRAPHQL_LIST_MEMBERS    = "/i/api/graphql/3dQPyRyAj6Lslp4e0ClXzg/ListMembers"
+	GRAPHQL_LIST_BY_REST_ID    = "/i/api/graphql/ZMQOSpxDo0cP5Cdt8MgEVA/ListByRestId"
+	GRAPHQL_LIST_MEMBERS       = "/i/api/graphql/3dQPyRyAj6Lslp4e0ClXzg/ListMembers"
+	GRAPHQL_LIST_OWNERSHIPS    = "/i/api/graphql/6PfS9_Z5Nw3QalFTaFkr4A/ListOwnerships"
+	GRAPHQL_LIST_SUBSCRIPTIONS = "/i/api/graphql/BQp2IEYkgxuSxqbTAr1e1g/ListSubscriptions"
 
 	// Legacy API endpoints
 	API_FRIENDSHIPS_CREATE = "/i/api/1.1/friendships/create.json"
+	// followed by the media key of a Space
+	API_LIVE_VIDEO_STREAM_STATUS = "/i/api/1.1/live_video_stream/status/"
 )
 
 // Response Path Constants
@@ -27,13 +43,24 @@ const (
 	INST_PATH_USER_MEDIA    = "data.user.result.timeline_v2.timeline.instructions"
 	INST_PATH_USER_TIMELINE = "data.user.result.timeline.timeline.instructions"
 	INST_PATH_LIST_MEMBERS  = "data.list.members_timeline.timeline.instructions"
+	INST_PATH_TWEET_DETAIL  = "data.threaded_conversation_with_injections_v2.instructions"
+	INST_PATH_SEARCH        = "data.search_by_raw_query.search_timeline.timeline.instructions"
+	INST_PATH_COMMUNITY     = "data.communityResults.result.ranked_community_timeline.timeline.instructions"
+
+	TWEET_RESULT_PATH = "data.tweetResult"
 )
 
 // Default Values
 const (
 	DEFAULT_PAGE_SIZE_FOR_TWEETS = 100
 	DEFAULT_MEMBERS_PAGE_SIZE    = 200
+	DEFAULT_SEARCH_PAGE_SIZE     = 20
+	DEFAULT_COMMUNITY_PAGE_SIZE  = 20
 	AVG_TWEETS_PER_PAGE          = 70
+	MAX_CONVERSATION_PAGES       = 20
+
+	// the ids of the tweets count the milliseconds since then
+	TWITTER_EPOCH_MILLI = 1288834974657
 )
 
 const AvgTweetsPerPage = 70
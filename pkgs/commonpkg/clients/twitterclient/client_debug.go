@@ -2,7 +2,6 @@ package twitterclient
 
 import (
 	"net/url"
-	"strings"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/tidwall/gjson"
@@ -15,7 +14,7 @@ func (c *Client) SetRequestCounting(cb func(path string)) {
 			return err
 		}
 
-		if strings.HasSuffix(url.Host, X_IMG) {
+		if isMediaHost(url.Hostname()) {
 			return nil
 		}
 
@@ -27,11 +26,12 @@ func (c *Client) SetRequestCounting(cb func(path string)) {
 ////////////////////////////////////////////////////////////////////////////////
 
 const (
-	ErrTimeout         = 29
-	ErrDependency      = 0
-	ErrExceedPostLimit = 88
-	ErrOverCapacity    = 130
-	ErrAccountLocked   = 326
+	ErrTimeout          = 29
+	ErrDependency       = 0
+	ErrAccountSuspended = 64
+	ErrExceedPostLimit  = 88
+	ErrOverCapacity     = 130
+	ErrAccountLocked    = 326
 )
 
 ////////////////////////////////////////////////////////////////////////////////
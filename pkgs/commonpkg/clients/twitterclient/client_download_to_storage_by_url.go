@@ -2,6 +2,7 @@ package twitterclient
 
 import (
 	"context"
+	"os"
 	"time"
 
 	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
@@ -57,6 +58,8 @@ func (c *Client) MustDownloadToStorageByUrl(ctx context.Context, url, targetPath
 		SetOutput(targetPath).
 		Get(url)
 	if err != nil {
+		// the body of a failed response is no media, nothing is left behind
+		os.Remove(targetPath)
 		return err
 	}
 	if resp.StatusCode() != 200 {
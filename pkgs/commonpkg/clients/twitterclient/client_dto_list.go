@@ -12,6 +12,12 @@ const (
 	TITLED_TYPE_TWITTER_USER      = "twitter_user"
 	TITLED_TYPE_TWITTER_LIST      = "twitter_list"
 	TITLED_TYPE_TWITTER_FOLLOWERS = "twitter_followers"
+
+	// pseudo lists group users that are no list on Twitter, their ids are
+	// those of what they come from
+	TITLED_TYPE_SEARCH           = "search"
+	TITLED_TYPE_COMMUNITY        = "community"
+	TITLED_TYPE_FOLLOWER_MEMBERS = "follower_members"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -62,43 +68,7 @@ func NewTulByRawListByteAndMembers(gjson *gjson.Result, members []*User) (*Title
 }
 
 func parseUserJsonForNewTulByTwitterListId(userJson *gjson.Result) (*User, error) {
-	result := userJson.Get("result")
-	if result.Get("__typename").String() == "UserUnavailable" {
-		return nil, fmt.Errorf("user unavailable")
-	}
-	legacy := result.Get("legacy")
-
-	restId := result.Get("rest_id")
-	friends_count := legacy.Get("friends_count")
-	name := legacy.Get("name")
-	screen_name := legacy.Get("screen_name")
-	protected := legacy.Get("protected").Exists() && legacy.Get("protected").Bool()
-	media_count := legacy.Get("media_count")
-	muting := legacy.Get("muting")
-	blocking := legacy.Get("blocking")
-
-	usr := User{}
-	if foll := legacy.Get("following"); foll.Exists() {
-		if foll.Bool() {
-			usr.Followstate = FS_FOLLOWING
-		} else {
-			usr.Followstate = FS_UNFOLLOW
-		}
-	} else if legacy.Get("follow_request_sent").Exists() {
-		usr.Followstate = FS_REQUESTED
-	} else {
-		usr.Followstate = FS_UNFOLLOW
-	}
-
-	usr.FriendsCount = int(friends_count.Int())
-	usr.TwitterId = restId.Uint()
-	usr.IsProtected = protected
-	usr.Name = name.String()
-	usr.ScreenName = screen_name.String()
-	usr.MediaCount = int(media_count.Int())
-	usr.Muting = muting.Exists() && muting.Bool()
-	usr.Blocking = blocking.Exists() && blocking.Bool()
-	return &usr, nil
+	return parseUserJson(userJson)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -113,3 +83,19 @@ func NewTulByUserAndFollowers(user *User, followers []*User) *TitledUserList {
 		TwitterName: user.Name,
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// NewPseudoTul groups users that are no list on Twitter, such as the authors
+// found by a search, so they are saved into a list folder named name. The
+// list folders are told apart by tulType and id, so ids of different types
+// may be the same.
+func NewPseudoTul(tulType string, id uint64, name string, users []*User) *TitledUserList {
+	return &TitledUserList{
+		Type:        tulType,
+		Id:          id,
+		Title:       fmt.Sprintf("%s(%d)", name, id),
+		Users:       users,
+		TwitterName: name,
+	}
+}
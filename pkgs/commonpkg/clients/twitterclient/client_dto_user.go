@@ -2,6 +2,8 @@ package twitterclient
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 // FollowState represents the follow relationship state between users
@@ -24,6 +26,15 @@ type User struct {
 	MediaCount   int         // Number of media posts by this user
 	Muting       bool        // Whether this user is muted
 	Blocking     bool        // Whether this user is blocked
+
+	Description      string    // Profile bio
+	Location         string    // Profile location
+	Url              string    // Profile website (expanded when available)
+	ProfileImageUrl  string    // Avatar URL as served in the profile (normal size)
+	ProfileBannerUrl string    // Banner URL, empty if the user has no banner
+	FollowersCount   int       // Number of accounts following this user
+	IsVerified       bool      // Whether the account is verified (legacy or blue)
+	CreatedAt        time.Time // When the account was created
 }
 
 func (user *User) IsVisiable() bool {
@@ -39,3 +50,23 @@ func (user *User) Title() string {
 func (user *User) IsUserVisible() bool {
 	return user.Followstate == FS_FOLLOWING || !user.IsProtected
 }
+
+// OriginalProfileImageUrl returns the full size avatar by dropping the "_normal" size suffix
+func (user *User) OriginalProfileImageUrl() string {
+	if user.ProfileImageUrl == "" {
+		return ""
+	}
+	idx := strings.LastIndex(user.ProfileImageUrl, "_normal")
+	if idx == -1 {
+		return user.ProfileImageUrl
+	}
+	return user.ProfileImageUrl[:idx] + user.ProfileImageUrl[idx+len("_normal"):]
+}
+
+// OriginalProfileBannerUrl returns the largest banner rendition
+func (user *User) OriginalProfileBannerUrl() string {
+	if user.ProfileBannerUrl == "" {
+		return ""
+	}
+	return user.ProfileBannerUrl + "/1500x500"
+}
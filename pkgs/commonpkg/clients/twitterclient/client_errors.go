@@ -1,15 +1,59 @@
 package twitterclient
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
 
 // Error definitions
 var (
 	ErrWouldBlock = fmt.Errorf("EWOULDBLOCK")
+
+	ErrSignedOut        = fmt.Errorf("cookie is expired or signed out")
+	ErrLockedAccount    = fmt.Errorf("account is locked")
+	ErrSuspendedAccount = fmt.Errorf("account is suspended")
+
+	ErrClientRetired = fmt.Errorf("client is retired, its cookie was removed")
+
+	ErrTweetUnavailable = fmt.Errorf("tweet is unavailable")
 )
 
 // isKnownError checks if an error is a known Twitter API error
 func isKnownError(err error) bool {
 	// This would need to be implemented based on the specific error types
-	// from the twitter package
-	return false
+	// from the twitter package. A replay would miss its recording again.
+	return errors.Is(err, ErrNotRecorded)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// PARSE_ERROR_MAX_VALUE is the most of the offending JSON kept in a ParseError
+const PARSE_ERROR_MAX_VALUE = 256
+
+// ParseError is a response the client does not know how to read. Path is the
+// gjson path of the offending value in the response.
+type ParseError struct {
+	Path   string
+	Reason string
+	Value  string
+}
+
+func (e *ParseError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("unexpected response at %s: %s", e.Path, e.Reason)
+	}
+	return fmt.Sprintf("unexpected response at %s: %s: %s", e.Path, e.Reason, e.Value)
+}
+
+func newParseError(path string, value gjson.Result, reason string) *ParseError {
+	return &ParseError{Path: path, Reason: reason, Value: truncateValue(value.Raw)}
+}
+
+func truncateValue(raw string) string {
+	if len(raw) <= PARSE_ERROR_MAX_VALUE {
+		return raw
+	}
+	return raw[:PARSE_ERROR_MAX_VALUE] + "..."
 }
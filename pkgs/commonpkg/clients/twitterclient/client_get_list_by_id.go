@@ -23,11 +23,11 @@ func (c *Client) GetRawListByteById(ctx context.Context, listId uint64) (*gjson.
 
 func (c *Client) getRawListByteById(ctx context.Context, path string, listId uint64) (*gjson.Result, error) {
 	u, _ := url.Parse(API_HOST)
-	u = u.JoinPath(path)
+	u = u.JoinPath(GraphQLPath(path))
 
 	params := url.Values{}
 	params.Set("variables", fmt.Sprintf(LIST_META_VARIABLES_FORM, listId))
-	params.Set("features", LIST_META_FEATURES)
+	params.Set("features", graphqlFeatures(path, LIST_META_FEATURES))
 
 	u.RawQuery = params.Encode()
 	requestUrl := u.String()
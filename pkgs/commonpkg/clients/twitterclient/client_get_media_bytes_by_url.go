@@ -0,0 +1,22 @@
+package twitterclient
+
+import (
+	"context"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+)
+
+// GetMediaBytesByUrl downloads media into memory, for callers that need to inspect it before saving
+func (c *Client) GetMediaBytesByUrl(ctx context.Context, url string) ([]byte, error) {
+	resp, err := c.restyClient.R().
+		SetContext(ctx).
+		Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.CheckRespStatus(resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Body(), nil
+}
@@ -16,17 +16,24 @@ var SCREEN_NAME_PATTERN = regexp.MustCompile(`"screen_name":"(\S+?)"`)
 // GetScreenName returns the screen name associated with the client
 func (c *Client) GetScreenName(ctx context.Context) (string, error) {
 	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	if c.screenName != "" {
-		return c.screenName, nil
+	screenName := c.screenName
+	c.mutex.RUnlock()
+	if screenName != "" {
+		return screenName, nil
 	}
 
+	// the lock is not held during the request, whose hooks take it
 	name, err := c.getScreenNameFromTwitter(ctx)
 	if err != nil {
 		return "", err
 	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.screenName = name
+	if store := getRateLimitStore(); store != nil && name != "" {
+		store.SetScreenName(AccountKey(c.authToken), name)
+	}
 	return c.screenName, nil
 }
 
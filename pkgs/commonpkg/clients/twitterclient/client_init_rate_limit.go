@@ -2,6 +2,7 @@ package twitterclient
 
 import (
 	"net/url"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	log "github.com/sirupsen/logrus"
@@ -16,6 +17,14 @@ func (c *Client) setRateLimit() {
 		return
 	}
 	c.rateLimiter = newRateLimiter(true)
+	if store := getRateLimitStore(); store != nil {
+		account := AccountKey(c.authToken)
+		rateLimiter := c.rateLimiter
+		rateLimiter.restore(store.Load(account))
+		rateLimiter.onUpdate = func() {
+			store.Save(account, rateLimiter.snapshot())
+		}
+	}
 
 	////////////////////////////////////////////////////////////////////////////
 
@@ -56,3 +65,24 @@ func (c *Client) WouldBlock(path string) bool {
 	defer c.mutex.RUnlock()
 	return c.rateLimiter.wouldBlock(path)
 }
+
+// RateLimits returns the quotas of the endpoints this client has requested
+func (c *Client) RateLimits() []RateLimit {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.rateLimiter.snapshot()
+}
+
+// AvailableAt returns when the client can request path again, the later of
+// the reset of its quota and the end of its quarantine. It is the zero time
+// when a request would go through now.
+func (c *Client) AvailableAt(path string) time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	at := c.rateLimiter.availableAt(path)
+	if time.Now().Before(c.quarantinedUntil) && c.quarantinedUntil.After(at) {
+		at = c.quarantinedUntil
+	}
+	return at
+}
@@ -28,6 +28,7 @@ func (c *Client) setTwitterIdenty(authToken string, ct0 string) {
 
 // setClientAuth configures authentication for the Twitter API client
 func (c *Client) setClientAuth(authToken string, ct0 string) {
+	c.authToken = authToken
 	c.restyClient.SetAuthToken(TWITTER_API_BEARER_TOKEN)
 	c.restyClient.SetCookie(&http.Cookie{
 		Name:  COOKIE_AUTH_TOKEN,
@@ -43,6 +44,7 @@ func (c *Client) setClientAuth(authToken string, ct0 string) {
 // configureErrorHandling sets up error handling for the client
 func (c *Client) configureErrorHandling() {
 	c.restyClient.OnAfterResponse(func(client *resty.Client, r *resty.Response) error {
+		c.countTooManyRequests(r)
 		// Import the CheckApiResp function from the twitter package
 		// This would need to be moved to a common package or imported
 		return utils.CheckRespStatus(r)
@@ -50,20 +52,28 @@ func (c *Client) configureErrorHandling() {
 }
 
 func (c *Client) configureRetryLogic() {
-	c.restyClient.SetRetryCount(5)
+	c.restyClient.SetRetryCount(RETRY_COUNT)
+	c.restyClient.SetRetryWaitTime(RETRY_MIN_WAIT)
+	// room for the jitter added to RETRY_MAX_WAIT
+	c.restyClient.SetRetryMaxWaitTime(2 * RETRY_MAX_WAIT)
+	c.restyClient.SetRetryAfter(c.retryAfterFunc)
 
 	c.restyClient.AddRetryCondition(func(r *resty.Response, err error) bool {
 		if err == ErrWouldBlock {
 			return false
 		}
+		// 429s are left to the condition below
+		if utils.IsStatusCode(err, http.StatusTooManyRequests) {
+			return false
+		}
 		// For TCP Error - would need to import TwitterApiError from twitter package
 		return err != nil && !isKnownError(err)
 	})
 
 	c.restyClient.AddRetryCondition(func(r *resty.Response, err error) bool {
-		// For Http 429
+		// For Http 429, unless the client was quarantined meanwhile
 		if httpErr, ok := err.(*utils.HttpStatusError); ok {
-			return r.Request.RawRequest.Host == "x.com" && httpErr.Code == 429
+			return r.Request.RawRequest.Host == "x.com" && httpErr.Code == 429 && c.IsAvailable()
 		}
 		return false
 	})
@@ -77,6 +87,6 @@ func (c *Client) configureTransport() {
 		IdleConnTimeout:       5 * time.Second,
 		TLSHandshakeTimeout:   5 * time.Second,
 		ResponseHeaderTimeout: 5 * time.Second,
-		Proxy:                 http.ProxyFromEnvironment,
+		Proxy:                 c.proxyOf,
 	})
 }
@@ -6,7 +6,6 @@ import (
 	"net/url"
 
 	log "github.com/sirupsen/logrus"
-	"github.com/tidwall/gjson"
 )
 
 type ListParams struct {
@@ -21,8 +20,8 @@ type ListParams struct {
 }
 
 // getTimelineItemContentsTillEnd retrieves all timeline item contents across multiple pages
-func (c *Client) getTimelineItemContentsTillEnd(ctx context.Context, path string, listParams ListParams, instPath string) ([]gjson.Result, error) {
-	res := make([]gjson.Result, 0)
+func (c *Client) getTimelineItemContentsTillEnd(ctx context.Context, path string, listParams ListParams, instPath string) ([]timelineItem, error) {
+	res := make([]timelineItem, 0)
 	for {
 		page, next, err := c.getTimelineItemContents(ctx, path, listParams, instPath)
 		if err != nil {
@@ -42,38 +41,12 @@ func (c *Client) getTimelineItemContentsTillEnd(ctx context.Context, path string
 
 // getTimelineItemContents retrieves timeline item contents for a single page
 // 获取时间线 API 并返回所有 itemContent 和 底部 cursor
-func (c *Client) getTimelineItemContents(ctx context.Context, path string, listParams ListParams, instPath string) ([]gjson.Result, string, error) {
+func (c *Client) getTimelineItemContents(ctx context.Context, path string, listParams ListParams, instPath string) ([]timelineItem, string, error) {
 	resp, err := c.getTimelineResp(ctx, path, listParams)
 	if err != nil {
 		return nil, "", err
 	}
-
-	// is temporarily unavailable because it violates the Twitter Media Policy.
-	// Protected User's following: Permission denied
-	if string(resp) == "{\"data\":{\"user\":{}}}" {
-		return nil, "", nil
-	}
-	instructions := getInstructions(resp, instPath)
-	entries := getEntries(instructions)
-	moduleItems := getModuleItems(instructions)
-	if !entries.Exists() && !moduleItems.Exists() {
-		panic(fmt.Sprintf("invalid instructions: %s", instructions.String()))
-	}
-
-	itemContents := make([]gjson.Result, 0)
-	if entries.IsArray() {
-		for _, entry := range entries.Array() {
-			if entry.Get("content.entryType").String() != "TimelineTimelineCursor" {
-				itemContents = append(itemContents, getItemContentsFromEntry(entry)...)
-			}
-		}
-	}
-	if moduleItems.IsArray() {
-		for _, moduleItem := range moduleItems.Array() {
-			itemContents = append(itemContents, getItemContentFromModuleItem(moduleItem))
-		}
-	}
-	return itemContents, getNextCursor(entries), nil
+	return parseTimelinePage(resp, instPath)
 }
 
 // getTimelineResp makes HTTP request to timeline API endpoint
@@ -86,14 +59,14 @@ func (c *Client) getTimelineResp(ctx context.Context, path string, listParams Li
 	})
 
 	u, _ := url.Parse(API_HOST)
-	u = u.JoinPath(path)
+	u = u.JoinPath(GraphQLPath(path))
 
 	params := url.Values{}
 	if listParams.VariablesForm != "" {
 		params.Set("variables", fmt.Sprintf(listParams.VariablesForm, listParams.Id, listParams.Count, listParams.Cursor))
 	}
 	if listParams.Features != "" {
-		params.Set("features", listParams.Features)
+		params.Set("features", graphqlFeatures(path, listParams.Features))
 	}
 	for k, v := range listParams.Extras {
 		params.Set(k, v)
@@ -108,14 +81,3 @@ func (c *Client) getTimelineResp(ctx context.Context, path string, listParams Li
 	}
 	return resp.Body(), nil
 }
-
-////////////////////////////////////////////////////////////////////////////////
-
-// getInstructions extracts instructions from Twitter API response
-func getInstructions(resp []byte, path string) gjson.Result {
-	inst := gjson.GetBytes(resp, path)
-	if !inst.Exists() {
-		panic(fmt.Sprintf("unable to get instructions: %s path: '%s'", resp, path))
-	}
-	return inst
-}
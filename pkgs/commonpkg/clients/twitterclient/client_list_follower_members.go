@@ -0,0 +1,71 @@
+package twitterclient
+
+import (
+	"context"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ListAllFollowerMembersByUserId returns the users following the user, unlike
+// ListAllFollowingMembersByUserId which returns those the user follows
+func (c *Client) ListAllFollowerMembersByUserId(ctx context.Context, userId uint64) ([]*User, error) {
+	return c.listAllFollowerMembers(ctx, GRAPHQL_FOLLOWERS, userId)
+}
+
+// ListAllVerifiedFollowerMembersByUserId returns the verified users following
+// the user
+func (c *Client) ListAllVerifiedFollowerMembersByUserId(ctx context.Context, userId uint64) ([]*User, error) {
+	return c.listAllFollowerMembers(ctx, GRAPHQL_VERIFIED_FOLLOWERS, userId)
+}
+
+func (c *Client) listAllFollowerMembers(ctx context.Context, path string, userId uint64) ([]*User, error) {
+	logger := log.WithField("caller", "Client.listAllFollowerMembers")
+
+	listParams := ListParams{
+		VariablesForm: USER_VARIABLES_FORM,
+		Features:      USER_FEATURES,
+
+		Id:     userId,
+		Count:  DEFAULT_MEMBERS_PAGE_SIZE,
+		Cursor: "",
+	}
+
+	itemContents, err := c.getTimelineItemContentsTillEnd(ctx, path, listParams, INST_PATH_USER_TIMELINE)
+	if err != nil {
+		// 403: Dmcaed
+		logger.WithError(err).Errorf("failed to get followers for user %d", userId)
+		if utils.IsStatusCode(err, 404) || utils.IsStatusCode(err, 403) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return itemContentsToUsers(itemContents), nil
+}
+
+func (c *Client) ListFollowerMembers(
+	ctx context.Context,
+	userId uint64,
+	pageSize int,
+	cursor string,
+) ([]*User, string, error) {
+	listParams := ListParams{
+		VariablesForm: USER_VARIABLES_FORM,
+		Features:      USER_FEATURES,
+
+		Id:     userId,
+		Count:  pageSize,
+		Cursor: cursor,
+	}
+
+	itemContents, nextCursor, err := c.getTimelineItemContents(ctx, GRAPHQL_FOLLOWERS, listParams, INST_PATH_USER_TIMELINE)
+	if err != nil {
+		return nil, "", err
+	}
+
+	users := itemContentsToUsers(itemContents)
+	return users, nextCursor, nil
+}
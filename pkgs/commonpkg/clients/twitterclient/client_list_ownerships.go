@@ -0,0 +1,68 @@
+package twitterclient
+
+import (
+	"context"
+
+	"github.com/tidwall/gjson"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	LIST_OWNERSHIPS_VARIABLES_FORM = `{"userId":"%d","count":%d,"cursor":"%s","isListMembershipShown":true,"isListMemberTargetUserId":"0"}`
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ListOwnerships returns the lists owned by the user, in the shape of
+// GetRawListByteById
+func (c *Client) ListOwnerships(ctx context.Context, userId uint64) ([]*gjson.Result, error) {
+	listParams := ListParams{
+		VariablesForm: LIST_OWNERSHIPS_VARIABLES_FORM,
+		Features:      USER_FEATURES,
+
+		Id:     userId,
+		Count:  DEFAULT_MEMBERS_PAGE_SIZE,
+		Cursor: "",
+	}
+
+	itemContents, err := c.getTimelineItemContentsTillEnd(ctx, GRAPHQL_LIST_OWNERSHIPS, listParams, INST_PATH_USER_TIMELINE)
+	if err != nil {
+		return nil, err
+	}
+	return itemContentsToRawLists(itemContents), nil
+}
+
+// ListSubscriptions returns the lists of others the user follows, in the
+// shape of GetRawListByteById
+func (c *Client) ListSubscriptions(ctx context.Context, userId uint64) ([]*gjson.Result, error) {
+	listParams := ListParams{
+		VariablesForm: USER_VARIABLES_FORM,
+		Features:      USER_FEATURES,
+
+		Id:     userId,
+		Count:  DEFAULT_MEMBERS_PAGE_SIZE,
+		Cursor: "",
+	}
+
+	itemContents, err := c.getTimelineItemContentsTillEnd(ctx, GRAPHQL_LIST_SUBSCRIPTIONS, listParams, INST_PATH_USER_TIMELINE)
+	if err != nil {
+		return nil, err
+	}
+	return itemContentsToRawLists(itemContents), nil
+}
+
+// itemContentsToRawLists returns the lists of the items, the other items are
+// counted in SkippedEntries
+func itemContentsToRawLists(itemContents []timelineItem) []*gjson.Result {
+	lists := make([]*gjson.Result, 0, len(itemContents))
+	for _, ic := range itemContents {
+		list := ic.content.Get("list")
+		if ic.content.Get("itemType").String() != "TimelineTwitterList" || !list.Get("id_str").Exists() {
+			skipEntry("item " + ic.content.Get("itemType").String())
+			continue
+		}
+		lists = append(lists, &list)
+	}
+	return lists
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
-	"github.com/tidwall/gjson"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -18,6 +17,13 @@ type Tweet struct {
 	CreatedAt time.Time // When the tweet was created
 	Creator   *User     // User who created the tweet
 	Urls      []string  // Media URLs associated with the tweet
+
+	CommunityId uint64 // Community the tweet was posted in, if any
+	SpaceId     string // Space shared by the tweet, if any
+
+	// unavailable is why the timeline held only the id of the tweet, which is
+	// then fetched on its own
+	unavailable string
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -35,7 +41,7 @@ func (c *Client) ListTweetsByUserAndTimeRange(ctx context.Context, user *User, t
 	cursor := ""
 
 	for {
-		currentTweets, next, err := c.ListTweets(
+		currentTweets, next, err := c.listTweetsPage(
 			ctx,
 			user.TwitterId,
 			DEFAULT_PAGE_SIZE_FOR_TWEETS,
@@ -53,12 +59,17 @@ func (c *Client) ListTweetsByUserAndTimeRange(ctx context.Context, user *User, t
 
 		// 筛选推文，并判断是否获取下页
 		cutMin, cutMax, currentTweets := filterTweetsByTimeRange(currentTweets, timeRange.Begin, timeRange.End)
+		inRange := len(currentTweets)
+		currentTweets, err = c.refetchUnavailableTweets(ctx, currentTweets)
+		if err != nil {
+			return nil, err
+		}
 		results = append(results, currentTweets...)
 
 		if cutMin {
 			break
 		}
-		if cutMax && len(currentTweets) != 0 {
+		if cutMax && inRange != 0 {
 			timeRange.End = time.Time{}
 		}
 	}
@@ -66,6 +77,20 @@ func (c *Client) ListTweetsByUserAndTimeRange(ctx context.Context, user *User, t
 }
 
 func (c *Client) ListTweets(ctx context.Context, userId uint64, pageSize int, cursor string) ([]*Tweet, string, error) {
+	tweets, next, err := c.listTweetsPage(ctx, userId, pageSize, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	tweets, err = c.refetchUnavailableTweets(ctx, tweets)
+	if err != nil {
+		return nil, "", err
+	}
+	return tweets, next, nil
+}
+
+// listTweetsPage returns a page of the media of the user, where the tweets
+// that cannot be seen are still to be fetched
+func (c *Client) listTweetsPage(ctx context.Context, userId uint64, pageSize int, cursor string) ([]*Tweet, string, error) {
 	// Build the request URL
 	requestUrl := c.buildUserMediaUrl(userId, pageSize, cursor)
 
@@ -80,7 +105,7 @@ func (c *Client) ListTweets(ctx context.Context, userId uint64, pageSize int, cu
 }
 
 func (c *Client) buildUserMediaUrl(userId uint64, pageSize int, cursor string) string {
-	baseUrl := API_HOST + GRAPHQL_USER_MEDIA
+	baseUrl := API_HOST + GraphQLPath(GRAPHQL_USER_MEDIA)
 
 	// Build query parameters
 	params := url.Values{}
@@ -91,7 +116,7 @@ func (c *Client) buildUserMediaUrl(userId uint64, pageSize int, cursor string) s
 
 	// Features parameter
 	features := `{"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_timeline_navigation_enabled":true,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"communities_web_enable_tweet_community_results_fetch":true,"c9s_tweet_anatomy_moderator_badge_enabled":true,"articles_preview_enabled":true,"tweetypie_unmention_optimization_enabled":true,"responsive_web_edit_tweet_api_enabled":true,"graphql_is_translatable_rweb_tweet_is_translatable_enabled":true,"view_counts_everywhere_api_enabled":true,"longform_notetweets_consumption_enabled":true,"responsive_web_twitter_article_tweet_consumption_enabled":true,"tweet_awards_web_tipping_enabled":false,"creator_subscriptions_quote_tweet_preview_enabled":false,"freedom_of_speech_not_reach_fetch_enabled":true,"standardized_nudges_misinfo":true,"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled":true,"rweb_video_timestamps_enabled":true,"longform_notetweets_rich_text_read_enabled":true,"longform_notetweets_inline_media_enabled":true,"responsive_web_enhance_cards_enabled":false}`
-	params.Set("features", features)
+	params.Set("features", graphqlFeatures(GRAPHQL_USER_MEDIA, features))
 
 	// Field toggles parameter
 	fieldToggles := `{"withArticlePlainText":false}`
@@ -105,44 +130,14 @@ func (c *Client) buildUserMediaUrl(userId uint64, pageSize int, cursor string) s
 
 // parseUserMediaResponse parses the user media API response
 func (c *Client) parseUserMediaResponse(body []byte) ([]*Tweet, string, error) {
-	// Check for protected user response
-	if string(body) == "{\"data\":{\"user\":{}}}" {
-		return nil, "", nil
-	}
-
-	// Parse instructions
-	instructions := gjson.GetBytes(body, INST_PATH_USER_MEDIA)
-	if !instructions.Exists() {
-		return nil, "", fmt.Errorf("unable to get instructions from response")
-	}
-
-	// Extract entries and items
-	entries := getEntries(instructions)
-	moduleItems := getModuleItems(instructions)
-
-	if !entries.Exists() && !moduleItems.Exists() {
-		return nil, "", fmt.Errorf("invalid instructions: no entries or module items found")
-	}
-
-	// Process item contents
-	itemContents := make([]gjson.Result, 0)
-	if entries.IsArray() {
-		for _, entry := range entries.Array() {
-			if entry.Get("content.entryType").String() != "TimelineTimelineCursor" {
-				itemContents = append(itemContents, getItemContentsFromEntry(entry)...)
-			}
-		}
+	items, nextCursor, err := parseTimelinePage(body, INST_PATH_USER_MEDIA)
+	if err != nil {
+		return nil, "", err
 	}
-	if moduleItems.IsArray() {
-		for _, moduleItem := range moduleItems.Array() {
-			itemContents = append(itemContents, getItemContentFromModuleItem(moduleItem))
-		}
+	tweets, err := itemContentsToTweets(items)
+	if err != nil {
+		return nil, "", err
 	}
-
-	// Convert to tweets and get next cursor
-	tweets := itemContentsToTweets(itemContents)
-	nextCursor := getNextCursor(entries)
-
 	return tweets, nextCursor, nil
 }
 
@@ -0,0 +1,95 @@
+package twitterclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+)
+
+// Options point a client at other servers than x.com, such as a fake one for
+// tests. The zero value talks to x.com.
+type Options struct {
+	// ApiHost replaces https://x.com, MediaHost the hosts of the media CDN
+	// under twimg.com and of the recorded Spaces under pscp.tv. Both are a
+	// scheme and a host, such as http://127.0.0.1:8080.
+	ApiHost   string
+	MediaHost string
+	// Transport carries the requests instead of the default transport, the
+	// proxies set by SetProxy are then up to it
+	Transport http.RoundTripper
+	// Recorder saves the traffic of the client, with the URLs of x.com
+	Recorder *Recorder
+}
+
+// NewWithOptions creates a client like New that requests through opts
+func NewWithOptions(authToken, ct0 string, opts Options) (*Client, error) {
+	apiHost, err := parseHost(opts.ApiHost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api host: %w", err)
+	}
+	mediaHost, err := parseHost(opts.MediaHost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media host: %w", err)
+	}
+
+	res := New(authToken, ct0)
+	if apiHost == nil && mediaHost == nil && opts.Transport == nil && opts.Recorder == nil {
+		return res, nil
+	}
+
+	transport := opts.Transport
+	if transport == nil {
+		transport = res.restyClient.GetClient().Transport
+	}
+	if apiHost != nil || mediaHost != nil {
+		transport = &hostTransport{
+			apiHost:   apiHost,
+			mediaHost: mediaHost,
+			next:      transport,
+		}
+	}
+	if opts.Recorder != nil {
+		transport = opts.Recorder.Transport(transport)
+	}
+	res.restyClient.SetTransport(transport)
+	return res, nil
+}
+
+func parseHost(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	return utils.ParseHostUrl(raw)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// hostTransport sends the requests for x.com and the media to the replacement
+// hosts. The rest of the client, the rate limits included, still sees the
+// original URLs.
+type hostTransport struct {
+	apiHost   *url.URL
+	mediaHost *url.URL
+	next      http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var host *url.URL
+	switch hostname := req.URL.Hostname(); {
+	case hostname == "x.com":
+		host = t.apiHost
+	case isMediaHost(hostname):
+		host = t.mediaHost
+	}
+	if host == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = host.Scheme
+	req.URL.Host = host.Host
+	req.Host = host.Host
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,243 @@
+package twitterclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient/fakex"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeXClient(t *testing.T) (*Client, *fakex.Server) {
+	server := fakex.New()
+	t.Cleanup(server.Close)
+
+	client, err := NewWithOptions("token", "ct0", Options{ApiHost: server.URL, MediaHost: server.URL})
+	require.NoError(t, err)
+	return client, server
+}
+
+func TestClientAgainstFakeX(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+
+	screenName, err := client.CheckAccount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fake_owner", screenName)
+
+	artist, err := client.GetUserByScreenName(ctx, "fake_artist")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1001), artist.TwitterId)
+	assert.Equal(t, FS_FOLLOWING, artist.Followstate)
+
+	tweets, err := client.ListTweetsByUserAndTimeRange(ctx, artist, utils.TimeRange{})
+	require.NoError(t, err)
+	require.Len(t, tweets, 3)
+	assert.Equal(t, uint64(1790000000000000301), tweets[0].Id)
+	// the media keep their CDN URLs, only the transport knows the fake
+	assert.Equal(t, []string{"https://pbs.twimg.com/media/GNfakeArtistB.jpg", "https://pbs.twimg.com/media/GNfakeArtistC.jpg"}, tweets[1].Urls)
+	assert.Equal(t, 3, server.Count("UserMedia"))
+
+	list, err := client.GetRawListByteById(ctx, 2001)
+	require.NoError(t, err)
+	assert.Equal(t, "Fake Artists", list.Get("name").String())
+	members, err := client.ListAllMembersByListId(ctx, 2001)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+	following, err := client.ListAllFollowingMembersByUserId(ctx, 1000)
+	require.NoError(t, err)
+	assert.Len(t, following, 2)
+
+	painter, err := client.GetUserById(ctx, 1002)
+	require.NoError(t, err)
+	painterTweets, err := client.ListTweetsByUserAndTimeRange(ctx, painter, utils.TimeRange{})
+	require.NoError(t, err)
+	// the tweet limited by its author is fetched on its own, the tombstone is
+	// left out
+	require.Len(t, painterTweets, 2)
+	assert.Equal(t, uint64(1790000000000000381), painterTweets[1].Id)
+	assert.Equal(t, []string{"https://pbs.twimg.com/media/GNfakePainterB.jpg"}, painterTweets[1].Urls)
+	assert.Equal(t, 2, server.Count("TweetResultByRestId"))
+	video := painterTweets[0].Urls[1]
+	assert.Equal(t, "https://video.twimg.com/ext_tw_video/1790000000000000412/pu/vid/avc1/1280x720/fakePainterTimelapse.mp4", video)
+
+	target := filepath.Join(t.TempDir(), "video.mp4")
+	require.NoError(t, client.MustDownloadToStorageByUrl(ctx, video, target, ""))
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "ftyp")
+
+	avatar, err := client.GetMediaBytesByUrl(ctx, painter.OriginalProfileImageUrl())
+	require.NoError(t, err)
+	assert.NotEmpty(t, avatar)
+
+	// the media CDN is left out of the rate limits
+	limits := client.RateLimits()
+	for _, limit := range limits {
+		assert.NotContains(t, limit.Path, "media")
+	}
+	assert.NotEmpty(t, limits)
+}
+
+func TestGetTweetDetail(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+
+	tweets, err := client.GetTweetDetail(ctx, 1790000000000000301, false)
+	require.NoError(t, err)
+	require.Len(t, tweets, 1)
+	assert.Equal(t, []string{"https://pbs.twimg.com/media/GNfakeArtistA.jpg"}, tweets[0].Urls)
+	assert.Equal(t, uint64(1001), tweets[0].Creator.TwitterId)
+
+	// the thread leaves out the replies of others and the related tweets
+	tweets, err = client.GetTweetDetail(ctx, 1790000000000000301, true)
+	require.NoError(t, err)
+	ids := make([]uint64, 0, len(tweets))
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.Id)
+	}
+	assert.Equal(t, []uint64{1790000000000000304, 1790000000000000302, 1790000000000000301}, ids)
+	assert.Equal(t, 3, server.Count("TweetDetail"))
+
+	_, err = client.GetTweetDetail(ctx, 1, true)
+	assert.ErrorIs(t, err, ErrTweetUnavailable)
+}
+
+func TestListFollowersAndLists(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+
+	followers, err := client.ListAllFollowerMembersByUserId(ctx, 1000)
+	require.NoError(t, err)
+	assert.Len(t, followers, 2)
+	verified, err := client.ListAllVerifiedFollowerMembersByUserId(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, verified, 1)
+	assert.Equal(t, "fake_painter", verified[0].ScreenName)
+	assert.Equal(t, 2, server.Count("Followers"))
+
+	owned, err := client.ListOwnerships(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, owned, 1)
+	assert.Equal(t, uint64(2001), owned[0].Get("id_str").Uint())
+	subscribed, err := client.ListSubscriptions(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, subscribed, 1)
+	// the lists are shaped like the one of GetRawListByteById
+	tul, err := NewTulByRawListByteAndMembers(subscribed[0], nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Fake Painters", tul.TwitterName)
+	assert.Equal(t, uint64(1002), tul.BelongsTo.TwitterId)
+}
+
+func TestCommunityAndSpace(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+
+	community, err := client.GetCommunityById(ctx, 3001)
+	require.NoError(t, err)
+	assert.Equal(t, "Fake Sketchers", community.Name)
+	_, err = client.GetCommunityById(ctx, 3002)
+	assert.Error(t, err)
+
+	tweets, err := client.ListCommunityTweetsByTimeRange(ctx, 3001, utils.TimeRange{})
+	require.NoError(t, err)
+	require.Len(t, tweets, 2)
+	assert.Equal(t, uint64(3001), tweets[0].CommunityId)
+	assert.Equal(t, "1fakeSpaceA01", tweets[1].SpaceId)
+	assert.Empty(t, tweets[1].Urls)
+	assert.Equal(t, 2, server.Count("CommunityTweetsTimeline"))
+
+	space, err := client.GetAudioSpaceById(ctx, tweets[1].SpaceId)
+	require.NoError(t, err)
+	assert.True(t, space.Replayable)
+	assert.Equal(t, uint64(1001), space.Creator.TwitterId)
+
+	// the master playlist leads to the one of the segments
+	target := filepath.Join(t.TempDir(), "space.aac")
+	require.NoError(t, client.DownloadSpace(ctx, space, target))
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(data), "fakeSpaceA01 chunk"))
+	assert.NoFileExists(t, target+".part")
+}
+
+func TestSearchTweetsByTimeRange(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+	query := "from:fake_artist OR from:fake_painter filter:media"
+
+	// the cursors of the later pages replace those of the first page
+	tweets, err := client.SearchTweetsByTimeRange(ctx, query, utils.TimeRange{})
+	require.NoError(t, err)
+	ids := make([]uint64, 0, len(tweets))
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.Id)
+	}
+	assert.Equal(t, []uint64{1790000000000000401, 1790000000000000301, 1790000000000000201}, ids)
+	assert.Equal(t, uint64(1002), tweets[0].Creator.TwitterId)
+	assert.Equal(t, 3, server.Count("SearchTimeline"))
+
+	// the older pages are not fetched
+	tweets, err = client.SearchTweetsByTimeRange(ctx, query, utils.TimeRange{Begin: tweets[1].CreatedAt})
+	require.NoError(t, err)
+	require.Len(t, tweets, 1)
+	assert.Equal(t, uint64(1790000000000000401), tweets[0].Id)
+	assert.Equal(t, 4, server.Count("SearchTimeline"))
+}
+
+func TestClientFakeXRateLimit(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+	server.SetRateLimit("UserByRestId", 3)
+
+	_, err := client.GetUserById(ctx, 1001)
+	require.NoError(t, err)
+	limits := client.RateLimits()
+	require.Len(t, limits, 1)
+	assert.Equal(t, GraphQLPath(GRAPHQL_USER_BY_REST_ID), limits[0].Path)
+	assert.Equal(t, 3, limits[0].Limit)
+	assert.Equal(t, 2, limits[0].Remaining)
+
+	// one request is kept in reserve, so the next one would block until the
+	// reset of the window
+	_, err = client.GetUserById(ctx, 1001)
+	require.NoError(t, err)
+	at := client.AvailableAt(GraphQLPath(GRAPHQL_USER_BY_REST_ID))
+	assert.WithinDuration(t, time.Now().Add(fakex.RATE_LIMIT_WINDOW), at, time.Minute)
+	_, err = client.GetUserById(ctx, 1001)
+	assert.ErrorContains(t, err, ErrWouldBlock.Error())
+	assert.Equal(t, 2, server.Count("UserByRestId"))
+}
+
+func TestClientFakeXRefetchRateLimit(t *testing.T) {
+	ctx := context.Background()
+	client, server := newFakeXClient(t)
+	server.SetRateLimit("TweetResultByRestId", 1)
+
+	painter, err := client.GetUserById(ctx, 1002)
+	require.NoError(t, err)
+
+	// the refetch over the quota is left out, not the whole timeline
+	tweets, err := client.ListTweetsByUserAndTimeRange(ctx, painter, utils.TimeRange{})
+	require.NoError(t, err)
+	var ids []uint64
+	for _, tweet := range tweets {
+		ids = append(ids, tweet.Id)
+	}
+	assert.Contains(t, ids, uint64(1790000000000000401))
+	assert.Equal(t, 1, server.Count("TweetResultByRestId"))
+}
+
+func TestNewWithOptionsInvalidHost(t *testing.T) {
+	_, err := NewWithOptions("token", "ct0", Options{MediaHost: "cdn"})
+	assert.Error(t, err)
+	_, err = NewWithOptions("token", "ct0", Options{ApiHost: "https://example.com/api"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+package twitterclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+)
+
+// PROXY_CHECK_TIMEOUT is how long signing in waits for the proxies to answer
+const PROXY_CHECK_TIMEOUT = 10 * time.Second
+
+// SetProxy sends the requests of the client through proxy, and the media
+// downloads from the CDN through mediaProxy. Either may be empty, the CDN then
+// goes through proxy and proxy falls back to HTTP_PROXY and HTTPS_PROXY.
+func (c *Client) SetProxy(proxy string, mediaProxy string) error {
+	var proxyUrl, mediaProxyUrl *url.URL
+	var err error
+	if proxy != "" {
+		if proxyUrl, err = utils.ParseProxyUrl(proxy); err != nil {
+			return err
+		}
+	}
+	if mediaProxy != "" {
+		if mediaProxyUrl, err = utils.ParseProxyUrl(mediaProxy); err != nil {
+			return err
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.proxy = proxyUrl
+	c.mediaProxy = mediaProxyUrl
+	return nil
+}
+
+// CheckProxy tells whether the proxies of the client accept connections
+func (c *Client) CheckProxy(ctx context.Context) error {
+	c.mutex.RLock()
+	proxies := []*url.URL{c.proxy, c.mediaProxy}
+	c.mutex.RUnlock()
+
+	for _, proxy := range proxies {
+		if proxy == nil {
+			continue
+		}
+		if err := utils.CheckProxyReachable(ctx, proxy, PROXY_CHECK_TIMEOUT); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxyOf picks the proxy of a request for the transport
+func (c *Client) proxyOf(req *http.Request) (*url.URL, error) {
+	c.mutex.RLock()
+	proxy, mediaProxy := c.proxy, c.mediaProxy
+	c.mutex.RUnlock()
+
+	if mediaProxy != nil && isMediaHost(req.URL.Hostname()) {
+		return mediaProxy, nil
+	}
+	if proxy != nil {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
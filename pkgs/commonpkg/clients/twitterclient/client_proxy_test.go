@@ -0,0 +1,53 @@
+package twitterclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newForwardProxy answers every request itself and records the hosts asked for
+func newForwardProxy(t *testing.T) (*httptest.Server, *[]string) {
+	hosts := &[]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*hosts = append(*hosts, r.URL.Host)
+	}))
+	t.Cleanup(server.Close)
+	return server, hosts
+}
+
+func TestClientProxy(t *testing.T) {
+	proxy, proxied := newForwardProxy(t)
+	mediaProxy, mediaProxied := newForwardProxy(t)
+
+	client := newSignedInClient("token", "user")
+	require.NoError(t, client.SetProxy(proxy.URL, mediaProxy.URL))
+	require.NoError(t, client.CheckProxy(context.Background()))
+
+	_, err := client.restyClient.R().Get("http://api.example.com/1.1/path")
+	require.NoError(t, err)
+	_, err = client.restyClient.R().Get("http://pbs.twimg.com/media/a.jpg")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"api.example.com"}, *proxied)
+	assert.Equal(t, []string{"pbs.twimg.com"}, *mediaProxied)
+
+	// without a media proxy the CDN goes through the proxy of the account
+	require.NoError(t, client.SetProxy(proxy.URL, ""))
+	_, err = client.restyClient.R().Get("http://pbs.twimg.com/media/b.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api.example.com", "pbs.twimg.com"}, *proxied)
+}
+
+func TestClientProxyInvalid(t *testing.T) {
+	client := newSignedInClient("token", "user")
+	assert.Error(t, client.SetProxy("ftp://proxy", ""))
+	assert.Error(t, client.SetProxy("", "proxy:8080"))
+
+	require.NoError(t, client.SetProxy("socks5://127.0.0.1:1", ""))
+	assert.ErrorContains(t, client.CheckProxy(context.Background()), "unreachable")
+}
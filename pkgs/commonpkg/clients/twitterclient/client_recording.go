@@ -0,0 +1,262 @@
+package twitterclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const REDACTED = "REDACTED"
+
+// ErrNotRecorded is returned by a replayer for the requests missing from its
+// recording
+var ErrNotRecorded = errors.New("request is not in the recording")
+
+// redactedHeaders carry the session of the account
+var redactedHeaders = []string{"Authorization", "Cookie", HEADER_CSRF_TOKEN, "Set-Cookie"}
+
+// Exchange is a request and its response as saved by a Recorder. A JSON body
+// is kept as is, any other one as text.
+type Exchange struct {
+	Method        string          `json:"method"`
+	Url           string          `json:"url"`
+	RequestHeader http.Header     `json:"request_header"`
+	Status        int             `json:"status"`
+	Header        http.Header     `json:"header"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	Text          string          `json:"text,omitempty"`
+}
+
+func (e *Exchange) body() []byte {
+	if e.Body != nil {
+		return e.Body
+	}
+	return []byte(e.Text)
+}
+
+// key identifies the request of the exchange regardless of the host and of
+// the query id of the GraphQL operation, which the recording may not share
+// with the replaying client
+func (e *Exchange) key() (string, error) {
+	u, err := url.Parse(e.Url)
+	if err != nil {
+		return "", err
+	}
+	return exchangeKey(e.Method, u), nil
+}
+
+func exchangeKey(method string, u *url.URL) string {
+	if !strings.HasPrefix(u.Path, "/i/api/graphql/") {
+		return method + " " + u.Path + "?" + u.Query().Encode()
+	}
+
+	variables := u.Query().Get("variables")
+	var parsed any
+	if err := json.Unmarshal([]byte(variables), &parsed); err == nil {
+		// the keys are sorted again
+		if data, err := json.Marshal(parsed); err == nil {
+			variables = string(data)
+		}
+	}
+	return method + " " + path.Base(u.Path) + " " + variables
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Recorder saves the GraphQL requests of the clients and their responses in a
+// dir, one file per exchange numbered in order, without the cookies and the
+// csrf token. The home page is saved as the screen name it holds, so a
+// replaying client can sign in.
+type Recorder struct {
+	dir   string
+	mutex sync.Mutex
+	seq   int
+}
+
+// NewRecorder records in dir, after the exchanges already there
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{dir: dir, seq: len(existing)}, nil
+}
+
+// Transport records what next carries
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{recorder: r, next: next}
+}
+
+func (r *Recorder) save(exchange *Exchange, operation string) error {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.seq++
+	name := fmt.Sprintf("%04d-%s.json", r.seq, operation)
+	r.mutex.Unlock()
+	return os.WriteFile(filepath.Join(r.dir, name), data, 0644)
+}
+
+type recordingTransport struct {
+	recorder *Recorder
+	next     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	var operation string
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/i/api/graphql/"):
+		operation = path.Base(req.URL.Path)
+	case req.URL.Path == "/home" && req.URL.Hostname() == "x.com":
+		operation = "home"
+	default:
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	if operation == "home" {
+		// the rest of the page is of no use to a replay
+		screenName := extractScreenNameFromHome(body)
+		body = nil
+		if screenName != "" {
+			body = fmt.Appendf(nil, `"screen_name":%q`, screenName)
+		}
+	}
+
+	exchange := &Exchange{
+		Method:        req.Method,
+		Url:           req.URL.String(),
+		RequestHeader: redactHeader(req.Header),
+		Status:        resp.StatusCode,
+		Header:        redactHeader(resp.Header),
+	}
+	exchange.Header.Del("Content-Length")
+	if json.Valid(body) {
+		exchange.Body = body
+	} else {
+		exchange.Text = string(body)
+	}
+	if err := t.recorder.save(exchange, operation); err != nil {
+		log.WithField("caller", "twitterclient.Recorder").Warnln("failed to save exchange:", err)
+	}
+	return resp, nil
+}
+
+func redactHeader(header http.Header) http.Header {
+	res := header.Clone()
+	if res == nil {
+		res = make(http.Header)
+	}
+	for _, name := range redactedHeaders {
+		if res.Get(name) != "" {
+			res.Set(name, REDACTED)
+		}
+	}
+	return res
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Replayer answers the requests of the clients from a recording, without
+// going out. A request recorded more than once gets the responses in order,
+// then the last one again. Everything else fails with ErrNotRecorded.
+type Replayer struct {
+	mutex     sync.Mutex
+	exchanges map[string][]*Exchange
+}
+
+// NewReplayer loads the recording in dir
+func NewReplayer(dir string) (*Replayer, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no recording in %s", dir)
+	}
+	sort.Strings(names)
+
+	res := &Replayer{exchanges: make(map[string][]*Exchange)}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		exchange := new(Exchange)
+		if err := json.Unmarshal(data, exchange); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		key, err := exchange.key()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		res.exchanges[key] = append(res.exchanges[key], exchange)
+	}
+	return res, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := exchangeKey(req.Method, req.URL)
+
+	r.mutex.Lock()
+	queue := r.exchanges[key]
+	if len(queue) == 0 {
+		r.mutex.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL.Path)
+	}
+	exchange := queue[0]
+	if len(queue) > 1 {
+		r.exchanges[key] = queue[1:]
+	}
+	r.mutex.Unlock()
+
+	body := exchange.body()
+	header := exchange.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,88 @@
+package twitterclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient/fakex"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	server := fakex.New()
+	defer server.Close()
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	client, err := NewWithOptions("secret-token", "secret-ct0", Options{
+		ApiHost:   server.URL,
+		MediaHost: server.URL,
+		Recorder:  recorder,
+	})
+	require.NoError(t, err)
+
+	_, err = client.CheckAccount(ctx)
+	require.NoError(t, err)
+	artist, err := client.GetUserByScreenName(ctx, "fake_artist")
+	require.NoError(t, err)
+	recorded, err := client.ListTweetsByUserAndTimeRange(ctx, artist, utils.TimeRange{})
+	require.NoError(t, err)
+	_, err = client.GetMediaBytesByUrl(ctx, artist.OriginalProfileImageUrl())
+	require.NoError(t, err)
+
+	// the media are left out
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	for i, name := range names {
+		names[i] = filepath.Base(name)
+	}
+	assert.Equal(t, []string{"0001-home.json", "0002-UserByScreenName.json", "0003-UserByScreenName.json", "0004-UserMedia.json", "0005-UserMedia.json", "0006-UserMedia.json"}, names)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+		assert.NotContains(t, string(data), server.URL)
+	}
+	home, err := os.ReadFile(filepath.Join(dir, "0001-home.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(home), `"text": "\"screen_name\":\"fake_owner\""`)
+
+	////////////////////////////////////////////////////////////////////////////
+
+	requests := len(server.Requests())
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	replaying, err := NewWithOptions("other-token", "other-ct0", Options{Transport: replayer})
+	require.NoError(t, err)
+
+	screenName, err := replaying.CheckAccount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fake_owner", screenName)
+	replayed, err := replaying.GetUserByScreenName(ctx, "fake_artist")
+	require.NoError(t, err)
+	assert.Equal(t, artist.TwitterId, replayed.TwitterId)
+	tweets, err := replaying.ListTweetsByUserAndTimeRange(ctx, replayed, utils.TimeRange{})
+	require.NoError(t, err)
+	require.Len(t, tweets, len(recorded))
+	for i := range tweets {
+		assert.Equal(t, recorded[i].Id, tweets[i].Id)
+		assert.Equal(t, recorded[i].Urls, tweets[i].Urls)
+	}
+	// the rate limits come from the recorded headers
+	assert.Equal(t, fakex.DEFAULT_RATE_LIMIT, replaying.RateLimits()[0].Limit)
+
+	_, err = replaying.GetUserById(ctx, 1002)
+	assert.ErrorContains(t, err, ErrNotRecorded.Error())
+	assert.Equal(t, requests, len(server.Requests()))
+}
+
+func TestNewReplayerEmpty(t *testing.T) {
+	_, err := NewReplayer(t.TempDir())
+	assert.Error(t, err)
+}
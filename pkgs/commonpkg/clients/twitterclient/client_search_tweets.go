@@ -0,0 +1,112 @@
+package twitterclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
+)
+
+// SearchTweetsByTimeRange retrieves the tweets found by a query of the search
+// of x.com, such as "from:a OR from:b filter:media", from the latest to the
+// earliest within the time range
+func (c *Client) SearchTweetsByTimeRange(ctx context.Context, query string, timeRange utils.TimeRange) ([]*Tweet, error) {
+	results := make([]*Tweet, 0)
+	cursor := ""
+
+	for {
+		currentTweets, next, err := c.searchTweetsPage(ctx, query, DEFAULT_SEARCH_PAGE_SIZE, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(currentTweets) == 0 || next == cursor {
+			break
+		}
+
+		cursor = next
+
+		cutMin, cutMax, currentTweets := filterTweetsByTimeRange(currentTweets, timeRange.Begin, timeRange.End)
+		inRange := len(currentTweets)
+		currentTweets, err = c.refetchUnavailableTweets(ctx, currentTweets)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, currentTweets...)
+
+		if cutMin {
+			break
+		}
+		if cutMax && inRange != 0 {
+			timeRange.End = time.Time{}
+		}
+	}
+	return results, nil
+}
+
+// SearchTweets retrieves a page of the latest tweets found by a query
+func (c *Client) SearchTweets(ctx context.Context, query string, pageSize int, cursor string) ([]*Tweet, string, error) {
+	tweets, next, err := c.searchTweetsPage(ctx, query, pageSize, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	tweets, err = c.refetchUnavailableTweets(ctx, tweets)
+	if err != nil {
+		return nil, "", err
+	}
+	return tweets, next, nil
+}
+
+// searchTweetsPage returns a page of the search, where the tweets that cannot
+// be seen are still to be fetched
+func (c *Client) searchTweetsPage(ctx context.Context, query string, pageSize int, cursor string) ([]*Tweet, string, error) {
+	// Make the API request
+	resp, err := c.restyClient.R().SetContext(ctx).Get(c.buildSearchTimelineUrl(query, pageSize, cursor))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Parse the response
+	items, next, err := parseTimelinePage(resp.Body(), INST_PATH_SEARCH)
+	if err != nil {
+		return nil, "", err
+	}
+	tweets, err := itemContentsToTweets(items)
+	if err != nil {
+		return nil, "", err
+	}
+	return tweets, next, nil
+}
+
+// buildSearchTimelineUrl constructs the URL for fetching a page of the latest
+// tweets found by a query
+func (c *Client) buildSearchTimelineUrl(query string, pageSize int, cursor string) string {
+	baseUrl := API_HOST + GraphQLPath(GRAPHQL_SEARCH_TIMELINE)
+
+	// Build query parameters
+	params := url.Values{}
+
+	// Variables parameter, the query is user input and is escaped as JSON
+	variables := map[string]any{
+		"rawQuery":    query,
+		"count":       pageSize,
+		"querySource": "typed_query",
+		"product":     "Latest",
+	}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+	variablesJson, _ := json.Marshal(variables)
+	params.Set("variables", string(variablesJson))
+
+	// Features parameter
+	features := `{"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_timeline_navigation_enabled":true,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"communities_web_enable_tweet_community_results_fetch":true,"c9s_tweet_anatomy_moderator_badge_enabled":true,"articles_preview_enabled":true,"tweetypie_unmention_optimization_enabled":true,"responsive_web_edit_tweet_api_enabled":true,"graphql_is_translatable_rweb_tweet_is_translatable_enabled":true,"view_counts_everywhere_api_enabled":true,"longform_notetweets_consumption_enabled":true,"responsive_web_twitter_article_tweet_consumption_enabled":true,"tweet_awards_web_tipping_enabled":false,"creator_subscriptions_quote_tweet_preview_enabled":false,"freedom_of_speech_not_reach_fetch_enabled":true,"standardized_nudges_misinfo":true,"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled":true,"rweb_video_timestamps_enabled":true,"longform_notetweets_rich_text_read_enabled":true,"longform_notetweets_inline_media_enabled":true,"responsive_web_enhance_cards_enabled":false}`
+	params.Set("features", graphqlFeatures(GRAPHQL_SEARCH_TIMELINE, features))
+
+	// Construct final URL
+	u, _ := url.Parse(baseUrl)
+	u.RawQuery = params.Encode()
+	return u.String()
+}
@@ -0,0 +1,207 @@
+package twitterclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	AUDIO_SPACE_VARIABLES_FORM = `{"id":"%s","isMetatagsQuery":false,"withReplays":true,"withListeners":true}`
+	AUDIO_SPACE_FEATURES       = `{"spaces_2022_h2_spacebar":true,"spaces_2022_h2_clipping":true,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"responsive_web_graphql_timeline_navigation_enabled":true}`
+
+	// MAX_PLAYLIST_DEPTH is how many master playlists may lead to the one of
+	// the segments
+	MAX_PLAYLIST_DEPTH = 2
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AudioSpace is a Space, whose audio can be downloaded once it ended if its
+// host recorded it
+type AudioSpace struct {
+	Id         string
+	Title      string
+	State      string
+	MediaKey   string
+	StartedAt  time.Time
+	Creator    *User // the host
+	Replayable bool
+}
+
+// GetAudioSpaceById returns the Space, an error if it does not exist
+func (c *Client) GetAudioSpaceById(ctx context.Context, spaceId string) (*AudioSpace, error) {
+	u, _ := url.Parse(API_HOST)
+	u = u.JoinPath(GraphQLPath(GRAPHQL_AUDIO_SPACE_BY_ID))
+
+	params := url.Values{}
+	params.Set("variables", fmt.Sprintf(AUDIO_SPACE_VARIABLES_FORM, spaceId))
+	params.Set("features", graphqlFeatures(GRAPHQL_AUDIO_SPACE_BY_ID, AUDIO_SPACE_FEATURES))
+	u.RawQuery = params.Encode()
+
+	resp, err := c.restyClient.R().SetContext(ctx).Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := gjson.GetBytes(resp.Body(), "data.audioSpace.metadata")
+	if !metadata.Get("media_key").Exists() {
+		return nil, fmt.Errorf("the space with ID %s doesn't exist", spaceId)
+	}
+	creator := metadata.Get("creator_results")
+	host, err := parseUserJson(&creator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the host of space %s: %w", spaceId, err)
+	}
+	return &AudioSpace{
+		Id:         metadata.Get("rest_id").String(),
+		Title:      metadata.Get("title").String(),
+		State:      metadata.Get("state").String(),
+		MediaKey:   metadata.Get("media_key").String(),
+		StartedAt:  time.UnixMilli(metadata.Get("started_at").Int()),
+		Creator:    host,
+		Replayable: metadata.Get("state").String() == "Ended" && metadata.Get("is_space_available_for_replay").Bool(),
+	}, nil
+}
+
+// GetSpacePlaylistUrl returns the URL of the HLS playlist of the recording of
+// a Space given by its media key
+func (c *Client) GetSpacePlaylistUrl(ctx context.Context, mediaKey string) (string, error) {
+	u, _ := url.Parse(API_HOST)
+	u = u.JoinPath(API_LIVE_VIDEO_STREAM_STATUS, mediaKey)
+
+	params := url.Values{}
+	params.Set("client", "web")
+	params.Set("use_syndication_guest_id", "false")
+	params.Set("cookie_set_host", "x.com")
+	u.RawQuery = params.Encode()
+
+	resp, err := c.restyClient.R().SetContext(ctx).Get(u.String())
+	if err != nil {
+		return "", err
+	}
+
+	location := gjson.GetBytes(resp.Body(), "source.location")
+	if location.String() == "" {
+		return "", newParseError("source.location", location, "no playlist")
+	}
+	return location.String(), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DownloadSpaceToStorage joins the segments of the HLS playlist of a recorded
+// Space into targetPath, which is left as is until every segment is there
+func (c *Client) DownloadSpaceToStorage(ctx context.Context, playlistUrl string, targetPath string) error {
+	logger := log.WithFields(log.Fields{
+		"caller":   "Client.DownloadSpaceToStorage",
+		"client":   c.screenName,
+		"playlist": playlistUrl,
+		"target":   targetPath,
+	})
+
+	segments, err := c.getPlaylistSegments(ctx, playlistUrl, MAX_PLAYLIST_DEPTH)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("no segment in playlist %s", playlistUrl)
+	}
+
+	partPath := targetPath + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partPath)
+	defer file.Close()
+
+	for _, segment := range segments {
+		data, err := c.GetMediaBytesByUrl(ctx, segment)
+		if err != nil {
+			return fmt.Errorf("failed to download segment %s: %w", segment, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, targetPath); err != nil {
+		return err
+	}
+
+	logger.WithField("segments", len(segments)).Debug("successfully downloaded space")
+	return nil
+}
+
+// getPlaylistSegments returns the URLs of the segments of a media playlist,
+// following the first variant of a master playlist
+func (c *Client) getPlaylistSegments(ctx context.Context, playlistUrl string, depth int) ([]string, error) {
+	base, err := url.Parse(playlistUrl)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.GetMediaBytesByUrl(ctx, playlistUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download playlist %s: %w", playlistUrl, err)
+	}
+	if !bytes.HasPrefix(data, []byte("#EXTM3U")) {
+		return nil, fmt.Errorf("invalid playlist %s", playlistUrl)
+	}
+
+	var segments []string
+	isVariant := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF") {
+			isVariant = true
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ref, err := url.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uri %q in playlist %s", line, playlistUrl)
+		}
+		uri := base.ResolveReference(ref).String()
+		if isVariant {
+			if depth == 0 {
+				return nil, fmt.Errorf("too many nested playlists at %s", playlistUrl)
+			}
+			return c.getPlaylistSegments(ctx, uri, depth-1)
+		}
+		segments = append(segments, uri)
+	}
+	return segments, scanner.Err()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DownloadSpace downloads the recording of the Space into targetPath, nothing
+// is done for the Spaces that cannot be replayed
+func (c *Client) DownloadSpace(ctx context.Context, space *AudioSpace, targetPath string) error {
+	if !space.Replayable {
+		return nil
+	}
+	playlistUrl, err := c.GetSpacePlaylistUrl(ctx, space.MediaKey)
+	if err != nil {
+		return err
+	}
+	// a recording taken down fails too, no file is left at targetPath
+	return c.DownloadSpaceToStorage(ctx, playlistUrl, targetPath)
+}
@@ -0,0 +1,109 @@
+package twitterclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+)
+
+// GetTweetById retrieves a tweet by its ID, or ErrTweetUnavailable when it
+// cannot be seen
+func (c *Client) GetTweetById(ctx context.Context, id uint64) (*Tweet, error) {
+	// Build the request URL
+	requestUrl := c.buildTweetByIdUrl(id)
+
+	// Make the API request
+	resp, err := c.restyClient.R().SetContext(ctx).Get(requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tweet [%d]: %w", id, err)
+	}
+
+	// Parse the response
+	return parseTweetResp(resp.Body(), id)
+}
+
+// buildTweetByIdUrl constructs the URL for fetching tweet by ID
+func (c *Client) buildTweetByIdUrl(tweetId uint64) string {
+	baseUrl := API_HOST + GraphQLPath(GRAPHQL_TWEET_RESULT_BY_REST_ID)
+
+	// Build query parameters
+	params := url.Values{}
+
+	// Variables parameter
+	variables := fmt.Sprintf(`{"tweetId":"%d","withCommunity":false,"includePromotedContent":false,"withVoice":false}`, tweetId)
+	params.Set("variables", variables)
+
+	// Features parameter
+	features := `{"creator_subscriptions_tweet_preview_api_enabled":true,"communities_web_enable_tweet_community_results_fetch":true,"c9s_tweet_anatomy_moderator_badge_enabled":true,"articles_preview_enabled":true,"tweetypie_unmention_optimization_enabled":true,"responsive_web_edit_tweet_api_enabled":true,"graphql_is_translatable_rweb_tweet_is_translatable_enabled":true,"view_counts_everywhere_api_enabled":true,"longform_notetweets_consumption_enabled":true,"responsive_web_twitter_article_tweet_consumption_enabled":true,"tweet_awards_web_tipping_enabled":false,"creator_subscriptions_quote_tweet_preview_enabled":false,"freedom_of_speech_not_reach_fetch_enabled":true,"standardized_nudges_misinfo":true,"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled":true,"rweb_video_timestamps_enabled":true,"longform_notetweets_rich_text_read_enabled":true,"longform_notetweets_inline_media_enabled":true,"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"responsive_web_graphql_timeline_navigation_enabled":true,"responsive_web_enhance_cards_enabled":false}`
+	params.Set("features", graphqlFeatures(GRAPHQL_TWEET_RESULT_BY_REST_ID, features))
+
+	// Field toggles parameter
+	fieldToggles := `{"withArticleRichContentState":true,"withArticlePlainText":false}`
+	params.Set("fieldToggles", fieldToggles)
+
+	// Construct final URL
+	u, _ := url.Parse(baseUrl)
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+// parseTweetResp parses the top-level JSON response to extract tweet data
+func parseTweetResp(resp []byte, id uint64) (*Tweet, error) {
+	if !gjson.ValidBytes(resp) {
+		return nil, &ParseError{Path: "@this", Reason: "invalid json", Value: truncateValue(string(resp))}
+	}
+	tweetResult := gjson.GetBytes(resp, TWEET_RESULT_PATH)
+	tweet, err := parseTweetResults(&tweetResult, TWEET_RESULT_PATH)
+	if err != nil {
+		return nil, err
+	}
+	if tweet == nil {
+		return nil, fmt.Errorf("%w: tweet [%d] does not exist", ErrTweetUnavailable, id)
+	}
+	if tweet.unavailable != "" {
+		return nil, fmt.Errorf("%w: tweet [%d] is a %s", ErrTweetUnavailable, id, tweet.unavailable)
+	}
+	return tweet, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// refetchUnavailableTweets fetches on their own the tweets a timeline held
+// only the ids of, such as some behind visibility wrappers, and leaves out
+// those still unavailable. A failed refetch leaves its tweet out too, rather
+// than the whole page, so only a canceled context is returned.
+func (c *Client) refetchUnavailableTweets(ctx context.Context, tweets []*Tweet) ([]*Tweet, error) {
+	logger := log.WithFields(log.Fields{
+		"caller": "Client.refetchUnavailableTweets",
+		"client": c.screenName,
+	})
+
+	res := make([]*Tweet, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet.unavailable == "" {
+			res = append(res, tweet)
+			continue
+		}
+
+		fetched, err := c.GetTweetById(ctx, tweet.Id)
+		if errors.Is(err, ErrTweetUnavailable) {
+			logger.Debugln("skipping tweet:", err)
+			skipEntry(tweet.unavailable)
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			logger.Warnf("skipping %s %d, failed to refetch it: %v", tweet.unavailable, tweet.Id, err)
+			skipEntry(tweet.unavailable)
+			continue
+		}
+		res = append(res, fetched)
+	}
+	return res, nil
+}
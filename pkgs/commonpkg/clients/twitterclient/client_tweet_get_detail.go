@@ -0,0 +1,143 @@
+package twitterclient
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// GetTweetDetail retrieves a tweet by its ID through its conversation. With
+// thread set, the tweets of its author above and below it in the conversation
+// come along, from the latest to the earliest.
+func (c *Client) GetTweetDetail(ctx context.Context, id uint64, thread bool) ([]*Tweet, error) {
+	var focal *Tweet
+	others := make([]*Tweet, 0)
+	seen := make(map[uint64]bool)
+	cursor := ""
+
+	for range MAX_CONVERSATION_PAGES {
+		tweets, next, err := c.getConversationPage(ctx, id, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, tweet := range tweets {
+			if seen[tweet.Id] {
+				continue
+			}
+			seen[tweet.Id] = true
+			found = true
+			if tweet.Id == id {
+				focal = tweet
+				continue
+			}
+			others = append(others, tweet)
+		}
+
+		// the rest of the conversation is only needed for the thread
+		if !thread || next == "" || !found {
+			break
+		}
+		cursor = next
+	}
+
+	if focal == nil {
+		return nil, fmt.Errorf("%w: tweet [%d] is not in its conversation", ErrTweetUnavailable, id)
+	}
+	if focal.unavailable != "" {
+		fetched, err := c.GetTweetById(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		focal = fetched
+	}
+
+	res := []*Tweet{focal}
+	if !thread {
+		return res, nil
+	}
+	for _, tweet := range others {
+		if tweet.unavailable != "" {
+			skipEntry(tweet.unavailable)
+			continue
+		}
+		if tweet.Creator == nil || focal.Creator == nil || tweet.Creator.TwitterId != focal.Creator.TwitterId {
+			continue
+		}
+		res = append(res, tweet)
+	}
+	slices.SortFunc(res, func(a, b *Tweet) int {
+		return cmp.Compare(b.Id, a.Id)
+	})
+	return res, nil
+}
+
+// getConversationPage returns the tweets of a page of the conversation of a
+// tweet and the cursor of the next page
+func (c *Client) getConversationPage(ctx context.Context, id uint64, cursor string) ([]*Tweet, string, error) {
+	// Make the API request
+	resp, err := c.restyClient.R().SetContext(ctx).Get(c.buildTweetDetailUrl(id, cursor))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get the conversation of tweet [%d]: %w", id, err)
+	}
+
+	// Parse the response
+	body := resp.Body()
+	if !gjson.GetBytes(body, INST_PATH_TWEET_DETAIL).Exists() {
+		// the deleted tweets answer an error and no data
+		if message := gjson.GetBytes(body, "errors.0.message"); message.Exists() {
+			return nil, "", fmt.Errorf("%w: tweet [%d]: %s", ErrTweetUnavailable, id, message.String())
+		}
+	}
+	items, next, err := parseTimelineInstructions(body, INST_PATH_TWEET_DETAIL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// the related tweets are suggestions, not part of the conversation
+	conversation := make([]timelineItem, 0, len(items))
+	for _, item := range items {
+		if !strings.HasPrefix(item.entryId, "tweet-") && !strings.HasPrefix(item.entryId, "conversationthread-") {
+			skipEntry("related tweet")
+			continue
+		}
+		conversation = append(conversation, item)
+	}
+	tweets, err := itemContentsToTweets(conversation)
+	if err != nil {
+		return nil, "", err
+	}
+	return tweets, next, nil
+}
+
+// buildTweetDetailUrl constructs the URL for fetching a page of the
+// conversation of a tweet
+func (c *Client) buildTweetDetailUrl(tweetId uint64, cursor string) string {
+	baseUrl := API_HOST + GraphQLPath(GRAPHQL_TWEET_DETAIL)
+
+	// Build query parameters
+	params := url.Values{}
+
+	// Variables parameter
+	variables := fmt.Sprintf(`{"focalTweetId":"%d","cursor":"%s","referrer":"tweet","with_rux_injections":false,"rankingMode":"Relevance","includePromotedContent":false,"withCommunity":true,"withQuickPromoteEligibilityTweetFields":false,"withBirdwatchNotes":false,"withVoice":true}`, tweetId, cursor)
+	params.Set("variables", variables)
+
+	// Features parameter
+	features := `{"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_timeline_navigation_enabled":true,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"communities_web_enable_tweet_community_results_fetch":true,"c9s_tweet_anatomy_moderator_badge_enabled":true,"articles_preview_enabled":true,"tweetypie_unmention_optimization_enabled":true,"responsive_web_edit_tweet_api_enabled":true,"graphql_is_translatable_rweb_tweet_is_translatable_enabled":true,"view_counts_everywhere_api_enabled":true,"longform_notetweets_consumption_enabled":true,"responsive_web_twitter_article_tweet_consumption_enabled":true,"tweet_awards_web_tipping_enabled":false,"creator_subscriptions_quote_tweet_preview_enabled":false,"freedom_of_speech_not_reach_fetch_enabled":true,"standardized_nudges_misinfo":true,"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled":true,"rweb_video_timestamps_enabled":true,"longform_notetweets_rich_text_read_enabled":true,"longform_notetweets_inline_media_enabled":true,"responsive_web_enhance_cards_enabled":false}`
+	params.Set("features", graphqlFeatures(GRAPHQL_TWEET_DETAIL, features))
+
+	// Field toggles parameter
+	fieldToggles := `{"withArticleRichContentState":true,"withArticlePlainText":false,"withGrokAnalyze":false,"withDisallowedReplyControls":false}`
+	params.Set("fieldToggles", fieldToggles)
+
+	// Construct final URL
+	u, _ := url.Parse(baseUrl)
+	u.RawQuery = params.Encode()
+	return u.String()
+}
@@ -0,0 +1,330 @@
+// Package fakex is a fake of x.com and its media CDN that serves recorded
+// responses, so the client and the CLI can be tested offline. Point a client
+// at it with the ApiHost and MediaHost options of twitterclient.
+package fakex
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DEFAULT_RATE_LIMIT is the quota of every GraphQL operation per
+	// RATE_LIMIT_WINDOW, unless SetRateLimit says otherwise
+	DEFAULT_RATE_LIMIT = 50
+	RATE_LIMIT_WINDOW  = 15 * time.Minute
+)
+
+const (
+	graphqlPrefix   = "/i/api/graphql/"
+	followPath      = "/i/api/1.1/friendships/create.json"
+	liveStreamPath  = "/i/api/1.1/live_video_stream/status/"
+	homePath        = "/home"
+	rateLimitedBody = `{"errors":[{"code":88,"message":"Rate limit exceeded."}]}`
+	csrfBody        = `{"errors":[{"code":353,"message":"This request requires a matching csrf cookie and header."}]}`
+)
+
+// fixtures holds a response per operation and lookup key, such as
+// UserByScreenName/fake_artist.json, pages after the first one suffixed by
+// their cursor. The live_video_stream dir holds the playlists of the Spaces
+// by media key. The cdn dir mirrors the paths of pbs.twimg.com,
+// video.twimg.com and of the recordings under video.pscp.tv.
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// lookupKeys are the variables the fixtures of each operation are named by
+var lookupKeys = map[string]string{
+	"UserByScreenName": "screen_name",
+	"UserByRestId":     "userId",
+	"UserMedia":        "userId",
+	"Following":        "userId",
+	"ListByRestId":     "listId",
+	"ListMembers":      "listId",
+
+	"Followers":             "userId",
+	"BlueVerifiedFollowers": "userId",
+	"ListOwnerships":        "userId",
+	"ListSubscriptions":     "userId",
+
+	"TweetResultByRestId": "tweetId",
+	"TweetDetail":         "focalTweetId",
+	"SearchTimeline":      "rawQuery",
+
+	"CommunityQuery":          "communityId",
+	"CommunityTweetsTimeline": "communityId",
+	"AudioSpaceById":          "id",
+}
+
+// emptyResults answer for the lookup keys without a fixture, the operations
+// missing here answer 404
+var emptyResults = map[string]string{
+	"UserByScreenName":    `{"data":{}}`,
+	"TweetResultByRestId": `{"data":{"tweetResult":{}}}`,
+	"TweetDetail":         `{"errors":[{"message":"_Missing: No status found with that ID.","code":144}],"data":{}}`,
+	"SearchTimeline":      `{"data":{"search_by_raw_query":{"search_timeline":{"timeline":{"instructions":[{"type":"TimelineAddEntries","entries":[]}]}}}}}`,
+	"CommunityQuery":      `{"data":{"communityResults":{"result":{"__typename":"CommunityUnavailable"}}}}`,
+	"AudioSpaceById":      `{"data":{"audioSpace":{}}}`,
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// Server answers like x.com for the signed in account fake_owner, and like
+// the media CDN for the files of the fixtures
+type Server struct {
+	*httptest.Server
+
+	fixtures fs.FS
+	mutex    sync.Mutex
+	limits   map[string]*rateLimit
+	quotas   map[string]int
+	failures map[string]int
+	requests []string
+}
+
+// New starts a server on the fixtures in this package, Close stops it
+func New() *Server {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return NewWithFixtures(sub)
+}
+
+// NewWithFixtures starts a server on other fixtures laid out the same way
+func NewWithFixtures(fixtures fs.FS) *Server {
+	s := &Server{
+		fixtures: fixtures,
+		limits:   make(map[string]*rateLimit),
+		quotas:   make(map[string]int),
+		failures: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetRateLimit changes the quota of an operation, starting a new window
+func (s *Server) SetRateLimit(operation string, limit int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.quotas[operation] = limit
+	delete(s.limits, operation)
+}
+
+// SetFailure makes an operation or a path answer the status code, 0 answers
+// it normally again
+func (s *Server) SetFailure(name string, code int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if code == 0 {
+		delete(s.failures, name)
+		return
+	}
+	s.failures[name] = code
+}
+
+// Requests returns what was asked for in order, GraphQL operations by name
+// and the rest by path
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Count returns how many times an operation or a path was asked for
+func (s *Server) Count(name string) int {
+	count := 0
+	for _, request := range s.Requests() {
+		if request == name {
+			count++
+		}
+	}
+	return count
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, graphqlPrefix):
+		s.serveGraphQL(w, r)
+	case r.URL.Path == followPath:
+		s.serveFollow(w, r)
+	case strings.HasPrefix(r.URL.Path, liveStreamPath):
+		s.record(r.URL.Path)
+		if !checkAuth(w, r) {
+			return
+		}
+		mediaKey := strings.TrimPrefix(r.URL.Path, liveStreamPath)
+		s.serveFile(w, path.Join("live_video_stream", fixtureName(mediaKey)+".json"), "application/json; charset=utf-8")
+	case r.URL.Path == homePath:
+		s.record(homePath)
+		if !checkAuth(w, r) {
+			return
+		}
+		s.serveFile(w, "home.html", "text/html; charset=utf-8")
+	default:
+		s.record(r.URL.Path)
+		if code := s.failure(r.URL.Path); code != 0 {
+			http.Error(w, "", code)
+			return
+		}
+		s.serveFile(w, path.Join("cdn", r.URL.Path), "")
+	}
+}
+
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	operation := path.Base(r.URL.Path)
+	s.record(operation)
+	if !checkAuth(w, r) || !s.consume(w, operation) {
+		return
+	}
+	if code := s.failure(operation); code != 0 {
+		http.Error(w, "", code)
+		return
+	}
+
+	key, ok := lookupKeys[operation]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	var variables map[string]any
+	if err := json.Unmarshal([]byte(r.URL.Query().Get("variables")), &variables); err != nil {
+		http.Error(w, `{"errors":[{"message":"invalid variables"}]}`, http.StatusBadRequest)
+		return
+	}
+
+	name := fmt.Sprint(variables[key])
+	if cursor, _ := variables["cursor"].(string); cursor != "" {
+		name += "-" + cursor
+	}
+	data, err := fs.ReadFile(s.fixtures, path.Join(operation, fixtureName(name)+".json"))
+	if empty, ok := emptyResults[operation]; ok && errors.Is(err, fs.ErrNotExist) {
+		// unknown screen names or tweets are an empty result, not a 404
+		data, err = []byte(empty), nil
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(data)
+}
+
+// fixtureName is the name of the fixture for a lookup key, followed by the
+// cursor of the page if any. The characters that cannot be embedded, such as
+// those of search queries, are replaced with "_".
+func fixtureName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func (s *Server) serveFollow(w http.ResponseWriter, r *http.Request) {
+	s.record(followPath)
+	if !checkAuth(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, `{"id_str":%q,"following":false,"follow_request_sent":true}`, r.FormValue("user_id"))
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, name string, contentType string) {
+	data, err := fs.ReadFile(s.fixtures, name)
+	if err != nil {
+		http.Error(w, "", http.StatusNotFound)
+		return
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+func (s *Server) record(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = append(s.requests, name)
+}
+
+// failure returns the status code set by SetFailure for name, 0 if none
+func (s *Server) failure(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.failures[name]
+}
+
+// consume spends a request of the quota of the operation and sets the rate
+// limit headers, answering 429 once the quota is spent
+func (s *Server) consume(w http.ResponseWriter, operation string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	limit, ok := s.limits[operation]
+	if !ok || !now.Before(limit.reset) {
+		quota, ok := s.quotas[operation]
+		if !ok {
+			quota = DEFAULT_RATE_LIMIT
+		}
+		limit = &rateLimit{limit: quota, remaining: quota, reset: now.Add(RATE_LIMIT_WINDOW)}
+		s.limits[operation] = limit
+	}
+
+	allowed := limit.remaining > 0
+	if allowed {
+		limit.remaining--
+	}
+	w.Header().Set("X-Rate-Limit-Limit", strconv.Itoa(limit.limit))
+	w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(limit.remaining))
+	w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(limit.reset.Unix(), 10))
+	if !allowed {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(rateLimitedBody))
+	}
+	return allowed
+}
+
+// checkAuth refuses the requests without the cookies of a session, or whose
+// csrf header does not match the ct0 cookie
+func checkAuth(w http.ResponseWriter, r *http.Request) bool {
+	authToken, err := r.Cookie("auth_token")
+	if err != nil || authToken.Value == "" {
+		http.Error(w, "", http.StatusUnauthorized)
+		return false
+	}
+	ct0, err := r.Cookie("ct0")
+	if err != nil || ct0.Value == "" || r.Header.Get("X-Csrf-Token") != ct0.Value {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(csrfBody))
+		return false
+	}
+	return true
+}
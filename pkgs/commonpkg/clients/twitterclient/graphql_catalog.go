@@ -0,0 +1,334 @@
+package twitterclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// GRAPHQL_CATALOG_TTL is how long the discovered query ids are trusted
+	// before the web bundle is fetched again
+	GRAPHQL_CATALOG_TTL = 24 * time.Hour
+	// GRAPHQL_CATALOG_MAX_BODY caps the pages read for the catalog, the main
+	// bundle weighs a few megabytes
+	GRAPHQL_CATALOG_MAX_BODY = 32 << 20
+)
+
+var (
+	mainBundleRegexp = regexp.MustCompile(`https://abs\.twimg\.com/responsive-web/client-web(?:-legacy)?/main\.[0-9a-z]+\.js`)
+	operationRegexp  = regexp.MustCompile(`queryId:"([\w-]+)",operationName:"(\w+)",operationType:"(\w+)",metadata:\{featureSwitches:\[([^\]]*)\]`)
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GraphQLOperation is a GraphQL endpoint as the web client calls it
+type GraphQLOperation struct {
+	QueryId         string   `json:"query_id"`
+	OperationType   string   `json:"operation_type"`
+	FeatureSwitches []string `json:"feature_switches"`
+}
+
+// GraphQLCatalog maps the operation names to their current query ids and
+// feature switches, discovered from the web client of x.com
+type GraphQLCatalog struct {
+	FetchedAt  time.Time                    `json:"fetched_at"`
+	BundleUrl  string                       `json:"bundle_url"`
+	Operations map[string]*GraphQLOperation `json:"operations"`
+	// Features are the default values of the feature switches
+	Features map[string]bool `json:"features"`
+}
+
+// Path returns the endpoint of the operation, false when it is unknown
+func (c *GraphQLCatalog) Path(operationName string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	op, ok := c.Operations[operationName]
+	if !ok || op.QueryId == "" {
+		return "", false
+	}
+	return fmt.Sprintf("/i/api/graphql/%s/%s", op.QueryId, operationName), true
+}
+
+// FeaturesOf returns the features JSON of the operation, one entry per switch
+// it declares. The defaults of the catalog win over the fallback, a switch
+// neither knows is off. The fallback is returned as is when the operation is
+// unknown.
+func (c *GraphQLCatalog) FeaturesOf(operationName string, fallback string) string {
+	if c == nil {
+		return fallback
+	}
+	op, ok := c.Operations[operationName]
+	if !ok || len(op.FeatureSwitches) == 0 {
+		return fallback
+	}
+
+	var fallbackValues map[string]bool
+	_ = json.Unmarshal([]byte(fallback), &fallbackValues)
+
+	features := make(map[string]bool, len(op.FeatureSwitches))
+	for _, name := range op.FeatureSwitches {
+		if value, ok := c.Features[name]; ok {
+			features[name] = value
+		} else {
+			features[name] = fallbackValues[name]
+		}
+	}
+	data, err := json.Marshal(features)
+	if err != nil {
+		return fallback
+	}
+	return string(data)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ParseHomePage finds the main bundle and the default feature switches in the
+// home page of x.com
+func ParseHomePage(html []byte) (bundleUrl string, features map[string]bool, err error) {
+	bundleUrl = string(mainBundleRegexp.Find(html))
+	if bundleUrl == "" {
+		return "", nil, errors.New("main bundle not found in the home page")
+	}
+	return bundleUrl, parseFeatureSwitches(html), nil
+}
+
+// parseFeatureSwitches reads the boolean switches of the defaultConfig in the
+// initial state of the page, the others are left out
+func parseFeatureSwitches(html []byte) map[string]bool {
+	features := make(map[string]bool)
+
+	page := string(html)
+	start := strings.Index(page, `"featureSwitch":`)
+	if start < 0 {
+		return features
+	}
+	offset := strings.Index(page[start:], `"defaultConfig":`)
+	if offset < 0 {
+		return features
+	}
+	object := matchBraces(page[start+offset+len(`"defaultConfig":`):])
+	if object == "" {
+		return features
+	}
+
+	var config map[string]struct {
+		Value any `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(object), &config); err != nil {
+		return features
+	}
+	for name, item := range config {
+		if value, ok := item.Value.(bool); ok {
+			features[name] = value
+		}
+	}
+	return features
+}
+
+// matchBraces returns the JSON object s starts with, empty when it does not
+// start with one or never closes it
+func matchBraces(s string) string {
+	s = strings.TrimLeft(s, " \t\r\n")
+	if !strings.HasPrefix(s, "{") {
+		return ""
+	}
+	depth := 0
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case inString && ch == '\\':
+			i++
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '{':
+			depth++
+		case ch == '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// ParseMainBundle reads the GraphQL operations declared in the main bundle of
+// the web client
+func ParseMainBundle(js []byte) (map[string]*GraphQLOperation, error) {
+	operations := make(map[string]*GraphQLOperation)
+	for _, match := range operationRegexp.FindAllSubmatch(js, -1) {
+		var switches []string
+		for _, name := range strings.Split(string(match[4]), ",") {
+			if name = strings.Trim(name, `" `); name != "" {
+				switches = append(switches, name)
+			}
+		}
+		operations[string(match[2])] = &GraphQLOperation{
+			QueryId:         string(match[1]),
+			OperationType:   string(match[3]),
+			FeatureSwitches: switches,
+		}
+	}
+	if len(operations) == 0 {
+		return nil, errors.New("no GraphQL operation found in the main bundle")
+	}
+	return operations, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// FetchGraphQLCatalog reads the home page at homeUrl then its main bundle
+func FetchGraphQLCatalog(ctx context.Context, httpClient *http.Client, homeUrl string) (*GraphQLCatalog, error) {
+	html, err := fetchPage(ctx, httpClient, homeUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the home page: %w", err)
+	}
+	bundleUrl, features, err := ParseHomePage(html)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := fetchPage(ctx, httpClient, bundleUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the main bundle: %w", err)
+	}
+	operations, err := ParseMainBundle(js)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphQLCatalog{
+		FetchedAt:  time.Now(),
+		BundleUrl:  bundleUrl,
+		Operations: operations,
+		Features:   features,
+	}, nil
+}
+
+func fetchPage(ctx context.Context, httpClient *http.Client, pageUrl string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(HEADER_USER_AGENT, USER_AGENT_1)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", pageUrl, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, GRAPHQL_CATALOG_MAX_BODY))
+}
+
+// LoadGraphQLCatalog returns the catalog cached at cachePath while it is
+// younger than ttl, otherwise it fetches a new one and caches it. A stale
+// cache is still returned when fetching fails.
+func LoadGraphQLCatalog(
+	ctx context.Context,
+	cachePath string,
+	ttl time.Duration,
+	fetch func(ctx context.Context) (*GraphQLCatalog, error),
+) (*GraphQLCatalog, error) {
+	logger := log.WithField("function", "LoadGraphQLCatalog")
+
+	cached, err := readGraphQLCatalog(cachePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warnln("ignoring the cached GraphQL catalog:", err)
+	}
+	if cached != nil && time.Since(cached.FetchedAt) < ttl {
+		return cached, nil
+	}
+
+	catalog, err := fetch(ctx)
+	if err != nil {
+		if cached != nil {
+			logger.Warnln("failed to refresh the GraphQL catalog, using the one of", cached.FetchedAt.Format(time.DateTime)+":", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	if err := writeGraphQLCatalog(cachePath, catalog); err != nil {
+		logger.Warnln("failed to cache the GraphQL catalog:", err)
+	}
+	return catalog, nil
+}
+
+func readGraphQLCatalog(cachePath string) (*GraphQLCatalog, error) {
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var catalog GraphQLCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+	return &catalog, nil
+}
+
+// writeGraphQLCatalog replaces the cache through a temporary file, so a crash
+// never leaves it half written
+func writeGraphQLCatalog(cachePath string, catalog *GraphQLCatalog) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cachePath)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+var (
+	graphqlCatalog      *GraphQLCatalog
+	graphqlCatalogMutex sync.RWMutex
+)
+
+// SetGraphQLCatalog makes every request afterwards use the query ids and the
+// feature switches of the catalog, the built-in ones fill in what it lacks
+func SetGraphQLCatalog(catalog *GraphQLCatalog) {
+	graphqlCatalogMutex.Lock()
+	defer graphqlCatalogMutex.Unlock()
+	graphqlCatalog = catalog
+}
+
+func getGraphQLCatalog() *GraphQLCatalog {
+	graphqlCatalogMutex.RLock()
+	defer graphqlCatalogMutex.RUnlock()
+	return graphqlCatalog
+}
+
+// GraphQLPath returns the current endpoint of the operation a built-in
+// GRAPHQL_* endpoint points to, the built-in one when the catalog lacks it
+func GraphQLPath(builtin string) string {
+	if p, ok := getGraphQLCatalog().Path(path.Base(builtin)); ok {
+		return p
+	}
+	return builtin
+}
+
+// graphqlFeatures returns the features JSON for the operation of a built-in
+// endpoint, with the built-in features as the fallback
+func graphqlFeatures(builtin string, fallback string) string {
+	return getGraphQLCatalog().FeaturesOf(path.Base(builtin), fallback)
+}
@@ -0,0 +1,214 @@
+package twitterclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "graphql", name))
+	require.NoError(t, err)
+	return data
+}
+
+func fixtureCatalog(t *testing.T) *GraphQLCatalog {
+	bundleUrl, features, err := ParseHomePage(readFixture(t, "home.html"))
+	require.NoError(t, err)
+	operations, err := ParseMainBundle(readFixture(t, "main.js"))
+	require.NoError(t, err)
+	return &GraphQLCatalog{
+		FetchedAt:  time.Now(),
+		BundleUrl:  bundleUrl,
+		Operations: operations,
+		Features:   features,
+	}
+}
+
+func TestParseHomePage(t *testing.T) {
+	bundleUrl, features, err := ParseHomePage(readFixture(t, "home.html"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://abs.twimg.com/responsive-web/client-web/main.8f21c0d9.js", bundleUrl)
+	assert.Equal(t, true, features["rweb_tipjar_consumption_enabled"])
+	assert.Equal(t, false, features["verified_phone_label_enabled"])
+	// only booleans are switches, and the user config does not override them
+	assert.NotContains(t, features, "dm_conversations_nsfw_media_filter_5578")
+	assert.NotContains(t, features, "grok_settings_age_restriction_enabled")
+	assert.NotContains(t, features, "responsive_web_home_pinned_timelines_max_count")
+	assert.Len(t, features, 33)
+
+	_, _, err = ParseHomePage([]byte("<html></html>"))
+	assert.Error(t, err)
+}
+
+func TestParseMainBundle(t *testing.T) {
+	operations, err := ParseMainBundle(readFixture(t, "main.js"))
+	require.NoError(t, err)
+
+	assert.Len(t, operations, 7)
+	userMedia := operations["UserMedia"]
+	require.NotNil(t, userMedia)
+	assert.Equal(t, "HaouMjBviBKKTYZGV_9qtg", userMedia.QueryId)
+	assert.Equal(t, "query", userMedia.OperationType)
+	assert.Len(t, userMedia.FeatureSwitches, 24)
+	assert.Equal(t, "rweb_video_screen_enabled", userMedia.FeatureSwitches[0])
+
+	assert.Equal(t, "Ktyc1sKkDVeZLZ6MjbDD-A", operations["ListMembers"].QueryId)
+	assert.Empty(t, operations["CreateBookmark"].FeatureSwitches)
+
+	_, err = ParseMainBundle([]byte("console.log(1)"))
+	assert.Error(t, err)
+}
+
+func TestGraphQLCatalogFeatures(t *testing.T) {
+	catalog := fixtureCatalog(t)
+	fallback := `{"rweb_tipjar_consumption_enabled":false,"responsive_web_grok_analyze_button_fetch_trends_enabled":true,"dropped_enabled":true}`
+
+	var features map[string]bool
+	require.NoError(t, json.Unmarshal([]byte(catalog.FeaturesOf("UserMedia", fallback)), &features))
+	assert.Len(t, features, 24)
+	// the defaults of the page win over the fallback
+	assert.Equal(t, true, features["rweb_tipjar_consumption_enabled"])
+	// a switch missing from the page takes the fallback, else it is off
+	assert.Equal(t, true, features["responsive_web_grok_analyze_button_fetch_trends_enabled"])
+	assert.Equal(t, false, features["rweb_video_screen_enabled"])
+	// the fallback does not add what the operation does not declare
+	assert.NotContains(t, features, "dropped_enabled")
+
+	assert.Equal(t, fallback, catalog.FeaturesOf("Likes", fallback))
+	assert.Equal(t, fallback, catalog.FeaturesOf("CreateBookmark", fallback))
+	assert.Equal(t, fallback, (*GraphQLCatalog)(nil).FeaturesOf("UserMedia", fallback))
+}
+
+func TestGraphQLPath(t *testing.T) {
+	SetGraphQLCatalog(nil)
+	t.Cleanup(func() { SetGraphQLCatalog(nil) })
+
+	assert.Equal(t, GRAPHQL_USER_MEDIA, GraphQLPath(GRAPHQL_USER_MEDIA))
+
+	SetGraphQLCatalog(fixtureCatalog(t))
+	assert.Equal(t, "/i/api/graphql/HaouMjBviBKKTYZGV_9qtg/UserMedia", GraphQLPath(GRAPHQL_USER_MEDIA))
+	assert.Equal(t, "/i/api/graphql/32pL5BWe9WKeSK1MoPvFQQ/UserByScreenName", GraphQLPath(GRAPHQL_USER_BY_SCREEN_NAME))
+	// Likes is not in the bundle, the built-in endpoint stays
+	assert.Equal(t, GRAPHQL_LIKES, GraphQLPath(GRAPHQL_LIKES))
+
+	client := newSignedInClient("token", "user")
+	u, err := url.Parse(client.buildUserMediaUrl(1, 20, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "/i/api/graphql/HaouMjBviBKKTYZGV_9qtg/UserMedia", u.Path)
+	assert.Contains(t, u.Query().Get("features"), `"rweb_video_screen_enabled":false`)
+}
+
+func TestManagerSelectsByCatalogPath(t *testing.T) {
+	SetGraphQLCatalog(fixtureCatalog(t))
+	t.Cleanup(func() { SetGraphQLCatalog(nil) })
+
+	manager := NewManager()
+	client := newSignedInClient("token", "user")
+	require.NoError(t, manager.AddClient(client))
+
+	// the limits are kept by the current endpoints, not the built-in ones
+	exhaust(client, GraphQLPath(GRAPHQL_USER_MEDIA), time.Now().Add(10*time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Nil(t, manager.SelectClientForMediaRequest(ctx))
+	assert.Same(t, client, manager.SelectClientForUserRequest(context.Background()))
+}
+
+// rewriteTransport sends every request to the server, keeping the path
+type rewriteTransport struct {
+	server *httptest.Server
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, _ := url.Parse(t.server.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme = target.Scheme
+	req.URL.Host = target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFetchGraphQLCatalog(t *testing.T) {
+	home, bundle := readFixture(t, "home.html"), readFixture(t, "main.js")
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get(HEADER_USER_AGENT))
+		switch r.URL.Path {
+		case "/":
+			w.Write(home)
+		case "/responsive-web/client-web/main.8f21c0d9.js":
+			w.Write(bundle)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &rewriteTransport{server}}
+
+	catalog, err := FetchGraphQLCatalog(context.Background(), httpClient, API_HOST)
+	require.NoError(t, err)
+	assert.Len(t, catalog.Operations, 7)
+	assert.Len(t, catalog.Features, 33)
+	assert.Equal(t, []string{USER_AGENT_1, USER_AGENT_1}, userAgents)
+
+	_, err = FetchGraphQLCatalog(context.Background(), httpClient, API_HOST+"/missing")
+	assert.Error(t, err)
+}
+
+func TestLoadGraphQLCatalog(t *testing.T) {
+	ctx := context.Background()
+	cachePath := filepath.Join(t.TempDir(), "graphql.json")
+	fetched := 0
+	fetchErr := error(nil)
+	fetch := func(ctx context.Context) (*GraphQLCatalog, error) {
+		fetched++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return fixtureCatalog(t), nil
+	}
+
+	// nothing cached and nothing fetched, the caller falls back to built-ins
+	fetchErr = errors.New("offline")
+	_, err := LoadGraphQLCatalog(ctx, cachePath, time.Hour, fetch)
+	assert.Error(t, err)
+	assert.NoFileExists(t, cachePath)
+
+	fetchErr = nil
+	catalog, err := LoadGraphQLCatalog(ctx, cachePath, time.Hour, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched)
+	assert.FileExists(t, cachePath)
+
+	// a fresh cache is used as is
+	cached, err := LoadGraphQLCatalog(ctx, cachePath, time.Hour, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched)
+	assert.Equal(t, catalog.Operations, cached.Operations)
+	assert.Equal(t, catalog.Features, cached.Features)
+
+	// a stale cache is refreshed, and still used when refreshing fails
+	fetchErr = errors.New("offline")
+	stale, err := LoadGraphQLCatalog(ctx, cachePath, 0, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetched)
+	assert.Equal(t, catalog.Operations, stale.Operations)
+
+	// a corrupted cache is ignored
+	require.NoError(t, os.WriteFile(cachePath, []byte("{"), 0644))
+	fetchErr = nil
+	_, err = LoadGraphQLCatalog(ctx, cachePath, time.Hour, fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 4, fetched)
+}
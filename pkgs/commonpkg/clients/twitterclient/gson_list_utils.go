@@ -2,68 +2,171 @@ package twitterclient
 
 import (
 	"fmt"
+	"strings"
+	"sync/atomic"
 
+	"github.com/WangWilly/xSync/pkgs/commonpkg/utils"
 	"github.com/tidwall/gjson"
 )
 
-// getItemContentFromModuleItem extracts item content from module items
-func getItemContentFromModuleItem(moduleItem gjson.Result) gjson.Result {
-	res := moduleItem.Get("item.itemContent")
-	if !res.Exists() {
-		panic(fmt.Errorf("invalid ModuleItem: %s", moduleItem.String()))
+// skippedEntries counts the entries left out of the timelines by kind
+var skippedEntries = utils.NewSyncMap[string, *atomic.Int32]()
+
+// SkippedEntries returns how many timeline entries were left out by kind since
+// the start, such as ads, tombstones or entries of unknown types
+func SkippedEntries() map[string]int32 {
+	res := make(map[string]int32)
+	for _, skipped := range skippedEntries.Range() {
+		res[skipped.Key] = skipped.Value.Load()
 	}
 	return res
 }
 
-// getItemContentsFromEntry extracts item contents from timeline entries
-func getItemContentsFromEntry(entry gjson.Result) []gjson.Result {
-	content := entry.Get("content")
-	entryType := content.Get("entryType").String()
-	switch entryType {
-	case "TimelineTimelineModule":
-		return content.Get("items.#.item.itemContent").Array()
-	case "TimelineTimelineItem":
-		return []gjson.Result{content.Get("itemContent")}
-	}
+func skipEntry(kind string) {
+	count, _ := skippedEntries.LoadOrStore(kind, &atomic.Int32{})
+	count.Add(1)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// timelineItem is the itemContent of an entry of a timeline, with its path in
+// the response and the entryId it came with
+type timelineItem struct {
+	path    string
+	entryId string
+	content gjson.Result
+}
 
-	panic(fmt.Sprintf("invalid entry: %s", entry.String()))
+// parseTimelinePage returns the items of a page of a timeline and the cursor
+// of the next page. Entries of unknown types are skipped and counted in
+// SkippedEntries, a response without the instructions of a timeline is a
+// ParseError.
+func parseTimelinePage(resp []byte, instPath string) ([]timelineItem, string, error) {
+	items, cursor, err := parseTimelineInstructions(resp, instPath)
+	if err != nil {
+		return nil, "", err
+	}
+	// without it the same page would be asked for again
+	if cursor == "" && len(items) != 0 {
+		return nil, "", newParseError(instPath, gjson.GetBytes(resp, instPath), "no bottom cursor")
+	}
+	return items, cursor, nil
 }
 
-// getModuleItems extracts module items from timeline instructions
-func getModuleItems(instructions gjson.Result) gjson.Result {
-	for _, inst := range instructions.Array() {
-		if inst.Get("type").String() == "TimelineAddToModule" {
-			return inst.Get("moduleItems")
+// parseTimelineInstructions is parseTimelinePage for the timelines whose last
+// page has no bottom cursor, such as conversations
+func parseTimelineInstructions(resp []byte, instPath string) ([]timelineItem, string, error) {
+	if !gjson.ValidBytes(resp) {
+		return nil, "", &ParseError{Path: "@this", Reason: "invalid json", Value: truncateValue(string(resp))}
+	}
+	// is temporarily unavailable because it violates the Twitter Media Policy.
+	// Protected User's following: Permission denied
+	if string(resp) == "{\"data\":{\"user\":{}}}" {
+		return nil, "", nil
+	}
+
+	instructions := gjson.GetBytes(resp, instPath)
+	if !instructions.IsArray() {
+		return nil, "", newParseError(instPath, instructions, "no instructions")
+	}
+
+	items := make([]timelineItem, 0)
+	cursor := ""
+	found := false
+	for i, inst := range instructions.Array() {
+		path := fmt.Sprintf("%s.%d", instPath, i)
+		// the other instructions, such as TimelineClearCache or
+		// TimelinePinEntry, bring nothing new
+		switch inst.Get("type").String() {
+		case "TimelineAddEntries":
+			found = true
+			for j, entry := range inst.Get("entries").Array() {
+				if next := getBottomCursor(entry); next != "" {
+					cursor = next
+					continue
+				}
+				items = appendEntryItems(items, entry, fmt.Sprintf("%s.entries.%d", path, j))
+			}
+		case "TimelineReplaceEntry":
+			// the later pages of a search replace their cursors
+			found = true
+			if next := getBottomCursor(inst.Get("entry")); next != "" {
+				cursor = next
+			}
+		case "TimelineAddToModule":
+			found = true
+			for j, moduleItem := range inst.Get("moduleItems").Array() {
+				items = appendItem(items, moduleItem.Get("item"), moduleItem.Get("entryId").String(), fmt.Sprintf("%s.moduleItems.%d.item", path, j))
+			}
 		}
 	}
-	return gjson.Result{}
+
+	if !found {
+		return nil, "", newParseError(instPath, instructions, "no entries nor module items")
+	}
+	return items, cursor, nil
 }
 
-// getEntries extracts entries from timeline instructions
-func getEntries(instructions gjson.Result) gjson.Result {
-	for _, inst := range instructions.Array() {
-		if inst.Get("type").String() == "TimelineAddEntries" {
-			return inst.Get("entries")
+// appendEntryItems appends the items of a timeline entry, a single one or
+// those of a module
+func appendEntryItems(items []timelineItem, entry gjson.Result, path string) []timelineItem {
+	entryId := entry.Get("entryId").String()
+	if strings.HasPrefix(entryId, "promoted") {
+		skipEntry("promoted")
+		return items
+	}
+
+	content := entry.Get("content")
+	entryType := content.Get("entryType").String()
+	if entryType == "" {
+		entryType = content.Get("__typename").String()
+	}
+	switch entryType {
+	case "TimelineTimelineItem":
+		return appendItem(items, content, entryId, path+".content")
+	case "TimelineTimelineModule":
+		for i, moduleItem := range content.Get("items").Array() {
+			items = appendItem(items, moduleItem.Get("item"), moduleItem.Get("entryId").String(), fmt.Sprintf("%s.content.items.%d.item", path, i))
 		}
+		return items
+	case "TimelineTimelineCursor":
+		return items
 	}
-	return gjson.Result{}
+
+	skipEntry("entry " + entryType)
+	return items
+}
+
+// appendItem appends the itemContent of an item unless it is an ad, a cursor
+// or of unknown shape
+func appendItem(items []timelineItem, item gjson.Result, entryId string, path string) []timelineItem {
+	content := item.Get("itemContent")
+	switch {
+	case !content.IsObject():
+		skipEntry("item without itemContent")
+	case content.Get("promotedMetadata").Exists():
+		skipEntry("promoted")
+	case content.Get("itemType").String() == "TimelineTimelineCursor":
+	default:
+		items = append(items, timelineItem{path: path + ".itemContent", entryId: entryId, content: content})
+	}
+	return items
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// getNextCursor extracts the next cursor for pagination
-func getNextCursor(entries gjson.Result) string {
-	array := entries.Array()
-	// if len(array) == 2 {
-	// 	return "" // no next page
-	// }
-
-	for i := len(array) - 1; i >= 0; i-- {
-		if array[i].Get("content.entryType").String() == "TimelineTimelineCursor" &&
-			array[i].Get("content.cursorType").String() == "Bottom" {
-			return array[i].Get("content.value").String()
+// getBottomCursor returns the cursor of the next page if the entry is it, as
+// an entry of its own or as the item of one
+func getBottomCursor(entry gjson.Result) string {
+	content := entry.Get("content")
+	if content.Get("entryType").String() != "TimelineTimelineCursor" {
+		content = content.Get("itemContent")
+		if content.Get("itemType").String() != "TimelineTimelineCursor" {
+			return ""
 		}
 	}
-
-	panic(fmt.Sprintf("invalid entries: %s", entries.String()))
+	if content.Get("cursorType").String() != "Bottom" {
+		return ""
+	}
+	return content.Get("value").String()
 }
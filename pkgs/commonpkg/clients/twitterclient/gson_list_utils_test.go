@@ -0,0 +1,157 @@
+package twitterclient
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCreatedAt    = "Wed May 15 08:00:00 +0000 2024"
+	testBottomCursor = `{"entryId":"cursor-bottom-1","content":{"entryType":"TimelineTimelineCursor","cursorType":"Bottom","value":"next"}}`
+)
+
+// userMediaPage is a page of UserMedia made of the instructions
+func userMediaPage(instructions ...string) []byte {
+	return fmt.Appendf(nil, `{"data":{"user":{"result":{"timeline_v2":{"timeline":{"instructions":[%s]}}}}}}`, strings.Join(instructions, ","))
+}
+
+func addEntries(entries ...string) string {
+	return fmt.Sprintf(`{"type":"TimelineAddEntries","entries":[%s]}`, strings.Join(entries, ","))
+}
+
+func tweetResult(id string, createdAt string) string {
+	return fmt.Sprintf(`{"__typename":"Tweet","rest_id":%q,"legacy":{"full_text":"tweet %s","created_at":%q,"extended_entities":{"media":[{"type":"photo","media_url_https":"https://pbs.twimg.com/media/%s.jpg"}]}}}`, id, id, createdAt, id)
+}
+
+func tweetEntry(entryId string, result string) string {
+	return fmt.Sprintf(`{"entryId":%q,"content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTweet","tweet_results":{"result":%s}}}}`, entryId, result)
+}
+
+func skippedSince(before map[string]int32) map[string]int32 {
+	res := make(map[string]int32)
+	for kind, count := range SkippedEntries() {
+		if delta := count - before[kind]; delta != 0 {
+			res[kind] = delta
+		}
+	}
+	return res
+}
+
+func TestParseTimelinePage(t *testing.T) {
+	before := SkippedEntries()
+	page := userMediaPage(
+		`{"type":"TimelineClearCache"}`,
+		addEntries(
+			tweetEntry("tweet-1", tweetResult("1", testCreatedAt)),
+			tweetEntry("promoted-tweet-2", tweetResult("2", testCreatedAt)),
+			`{"entryId":"who-to-follow-3","content":{"entryType":"TimelineTimelineMessagePrompt"}}`,
+			fmt.Sprintf(`{"entryId":"profile-grid-0","content":{"entryType":"TimelineTimelineModule","items":[{"item":{"itemContent":{"itemType":"TimelineTweet","tweet_results":{"result":%s}}}},{"item":{"itemContent":{"itemType":"TimelineTweet","promotedMetadata":{},"tweet_results":{}}}},{"item":{}}]}}`, tweetResult("4", testCreatedAt)),
+			`{"entryId":"cursor-top-1","content":{"entryType":"TimelineTimelineCursor","cursorType":"Top","value":"previous"}}`,
+			testBottomCursor,
+		),
+		fmt.Sprintf(`{"type":"TimelineAddToModule","moduleItems":[{"item":{"itemContent":{"itemType":"TimelineTweet","tweet_results":{"result":%s}}}}]}`, tweetResult("5", testCreatedAt)),
+	)
+
+	items, cursor, err := parseTimelinePage(page, INST_PATH_USER_MEDIA)
+	require.NoError(t, err)
+	assert.Equal(t, "next", cursor)
+	require.Len(t, items, 3)
+	assert.Equal(t, INST_PATH_USER_MEDIA+".1.entries.0.content.itemContent", items[0].path)
+	assert.Equal(t, INST_PATH_USER_MEDIA+".1.entries.3.content.items.0.item.itemContent", items[1].path)
+	assert.Equal(t, INST_PATH_USER_MEDIA+".2.moduleItems.0.item.itemContent", items[2].path)
+	assert.Equal(t, map[string]int32{
+		"promoted":                            2,
+		"entry TimelineTimelineMessagePrompt": 1,
+		"item without itemContent":            1,
+	}, skippedSince(before))
+
+	tweets, err := itemContentsToTweets(items)
+	require.NoError(t, err)
+	require.Len(t, tweets, 3)
+	assert.Equal(t, []uint64{1, 4, 5}, []uint64{tweets[0].Id, tweets[1].Id, tweets[2].Id})
+}
+
+func TestParseTimelinePageCursorItem(t *testing.T) {
+	page := userMediaPage(addEntries(
+		tweetEntry("tweet-1", tweetResult("1", testCreatedAt)),
+		`{"entryId":"cursor-bottom-1","content":{"entryType":"TimelineTimelineItem","itemContent":{"itemType":"TimelineTimelineCursor","cursorType":"Bottom","value":"next"}}}`,
+	))
+	items, cursor, err := parseTimelinePage(page, INST_PATH_USER_MEDIA)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "next", cursor)
+}
+
+func TestParseTimelinePageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+		path string
+	}{
+		{"invalid json", `{"data":`, "@this"},
+		{"no instructions", `{"data":{"user":{"result":{"__typename":"User"}}}}`, INST_PATH_USER_MEDIA},
+		{"instructions of another type", `{"data":{"user":{"result":{"timeline_v2":{"timeline":{"instructions":{}}}}}}}`, INST_PATH_USER_MEDIA},
+		{"no entries", string(userMediaPage(`{"type":"TimelineClearCache"}`)), INST_PATH_USER_MEDIA},
+		{"no bottom cursor", string(userMediaPage(addEntries(tweetEntry("tweet-1", tweetResult("1", testCreatedAt))))), INST_PATH_USER_MEDIA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseTimelinePage([]byte(tt.resp), INST_PATH_USER_MEDIA)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.path, parseErr.Path)
+		})
+	}
+
+	// the protected users are an empty page
+	items, cursor, err := parseTimelinePage([]byte(`{"data":{"user":{}}}`), INST_PATH_USER_MEDIA)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Empty(t, cursor)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func FuzzParseTimelinePage(f *testing.F) {
+	seeds, err := filepath.Glob("fakex/fixtures/*/*.json")
+	require.NoError(f, err)
+	for _, seed := range seeds {
+		data, err := os.ReadFile(seed)
+		require.NoError(f, err)
+		f.Add(data)
+	}
+	f.Add(userMediaPage(addEntries(
+		tweetEntry("tweet-1", `{"__typename":"TweetWithVisibilityResults","tweet":{"__typename":"TweetWithVisibilityResults","tweet":`+tweetResult("1", testCreatedAt)+`}}`),
+		tweetEntry("tweet-2", `{"__typename":"TweetTombstone"}`),
+		tweetEntry("tweet-3", tweetResult("3", "yesterday")),
+		testBottomCursor,
+	)))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, instPath := range []string{INST_PATH_USER_MEDIA, INST_PATH_USER_TIMELINE, INST_PATH_LIST_MEMBERS} {
+			items, _, err := parseTimelinePage(data, instPath)
+			assertParseError(t, err)
+			if err != nil {
+				continue
+			}
+			_, err = itemContentsToTweets(items)
+			assertParseError(t, err)
+			itemContentsToUsers(items)
+		}
+		parseUserResp(data)
+	})
+}
+
+func assertParseError(t *testing.T, err error) {
+	var parseErr *ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		t.Fatalf("not a ParseError: %v", err)
+	}
+}
@@ -2,66 +2,140 @@ package twitterclient
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/tidwall/gjson"
 )
 
-// itemContentsToTweets converts timeline item contents to Tweet objects
-func itemContentsToTweets(itemContents []gjson.Result) []*Tweet {
+// itemContentsToTweets converts timeline item contents to Tweet objects,
+// leaving out the items that are not tweets. The tweets that cannot be seen
+// are kept as their ids to be fetched on their own.
+func itemContentsToTweets(itemContents []timelineItem) ([]*Tweet, error) {
 	res := make([]*Tweet, 0, len(itemContents))
 	for _, itemContent := range itemContents {
-		tweetResults := getResults(itemContent, timelineTweet)
-		if tw := parseTweetResults(&tweetResults); tw != nil {
-			res = append(res, tw)
+		if itemType := itemContent.content.Get("itemType").String(); itemType != "" && itemType != "TimelineTweet" {
+			skipEntry("item " + itemType)
+			continue
 		}
+		tweetResults := getResults(itemContent.content, timelineTweet)
+		tw, err := parseTweetResults(&tweetResults, itemContent.path+".tweet_results")
+		if err != nil {
+			return nil, err
+		}
+		if tw == nil {
+			continue
+		}
+		if tw.unavailable != "" {
+			// the tombstones hold no rest_id, the entries still do
+			if tw.Id == 0 {
+				tw.Id = entryIdToTweetId(itemContent.entryId)
+			}
+			if tw.Id == 0 {
+				skipEntry(tw.unavailable)
+				continue
+			}
+			// so that it is placed by the time ranges before being fetched
+			tw.CreatedAt = snowflakeTime(tw.Id)
+		}
+		res = append(res, tw)
 	}
-	return res
+	return res, nil
 }
 
-// parseTweetResults parses tweet data from Twitter API JSON response
-func parseTweetResults(tweet_results *gjson.Result) *Tweet {
+// entryIdToTweetId returns the tweet id an entryId such as "tweet-1" or
+// "profile-grid-0-tweet-1" ends with, or 0
+func entryIdToTweetId(entryId string) uint64 {
+	i := strings.LastIndex(entryId, "tweet-")
+	if i < 0 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(entryId[i+len("tweet-"):], 10, 64)
+	return id
+}
+
+// snowflakeTime returns when the tweet of the id was created
+func snowflakeTime(id uint64) time.Time {
+	return time.UnixMilli(int64(id>>22) + TWITTER_EPOCH_MILLI)
+}
+
+// parseTweetResults parses tweet data from Twitter API JSON response, path is
+// where tweet_results is in the response. The tweets that cannot be seen hold
+// only their id, if any, and why; the results without a tweet are nil.
+func parseTweetResults(tweet_results *gjson.Result, path string) (*Tweet, error) {
 	var tweet Tweet
 	var err error = nil
 
 	result := tweet_results.Get("result")
-	if !result.Exists() || result.Get("__typename").String() == "TweetTombstone" {
-		return nil
-	}
-	if result.Get("__typename").String() == "TweetWithVisibilityResults" {
+	path += ".result"
+	// the tweets limited by their author are wrapped, at times more than once
+	for result.Get("__typename").String() == "TweetWithVisibilityResults" {
 		result = result.Get("tweet")
+		path += ".tweet"
+	}
+	switch typename := result.Get("__typename").String(); {
+	case !result.IsObject():
+		skipEntry("tweet without result")
+		return nil, nil
+	case typename == "TweetTombstone" || typename == "TweetUnavailable":
+		return &Tweet{Id: result.Get("rest_id").Uint(), unavailable: typename}, nil
 	}
 	legacy := result.Get("legacy")
-	// TODO: 利用 rest_id 重新获取推文信息
-	if !legacy.Exists() {
-		return nil
+	if !legacy.IsObject() {
+		return &Tweet{Id: result.Get("rest_id").Uint(), unavailable: "tweet without legacy"}, nil
 	}
 	user_results := result.Get("core.user_results")
 
-	tweet.Id = result.Get("rest_id").Uint()
+	restId := result.Get("rest_id")
+	tweet.Id = restId.Uint()
+	if tweet.Id == 0 {
+		return nil, newParseError(path+".rest_id", restId, "invalid tweet id")
+	}
 	tweet.Text = legacy.Get("full_text").String()
 	tweet.Creator, _ = parseUserJson(&user_results)
-	tweet.CreatedAt, err = time.Parse(time.RubyDate, legacy.Get("created_at").String())
+	createdAt := legacy.Get("created_at")
+	tweet.CreatedAt, err = time.Parse(time.RubyDate, createdAt.String())
 	if err != nil {
-		panic(fmt.Errorf("invalid time format %v", err))
+		return nil, newParseError(path+".legacy.created_at", createdAt, fmt.Sprintf("invalid time format %v", err))
 	}
 	media := legacy.Get("extended_entities.media")
 	if media.Exists() {
 		tweet.Urls = getUrlsFromMedia(&media)
 	}
-	return &tweet
+	tweet.CommunityId = result.Get("community_results.result.id_str").Uint()
+	tweet.SpaceId = getSpaceIdFromCard(result.Get("card.legacy"))
+	return &tweet, nil
+}
+
+// getSpaceIdFromCard returns the id of the Space the card of a tweet shows, if
+// it is one, such as 1YqKDqWqdPLJV
+func getSpaceIdFromCard(card gjson.Result) string {
+	if !strings.HasSuffix(card.Get("name").String(), ":audiospace") {
+		return ""
+	}
+	for _, binding := range card.Get("binding_values").Array() {
+		if binding.Get("key").String() == "id" {
+			return binding.Get("value.string_value").String()
+		}
+	}
+	return ""
 }
 
 // getUrlsFromMedia extracts media URLs from tweet media entities
 func getUrlsFromMedia(media *gjson.Result) []string {
 	results := []string{}
 	for _, m := range media.Array() {
+		var url string
 		typ := m.Get("type").String()
 		switch typ {
 		case "video", "animated_gif":
-			results = append(results, m.Get("video_info.variants.@reverse.0.url").String())
+			url = m.Get("video_info.variants.@reverse.0.url").String()
 		case "photo":
-			results = append(results, m.Get("media_url_https").String())
+			url = m.Get("media_url_https").String()
+		}
+		if url != "" {
+			results = append(results, url)
 		}
 	}
 	return results
@@ -0,0 +1,106 @@
+package twitterclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestParseTweetResults(t *testing.T) {
+	parse := func(result string) (*Tweet, error) {
+		tweetResults := gjson.Parse(`{"result":` + result + `}`)
+		return parseTweetResults(&tweetResults, "tweet_results")
+	}
+
+	tweet, err := parse(tweetResult("1", testCreatedAt))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), tweet.Id)
+	assert.Equal(t, 2024, tweet.CreatedAt.Year())
+	assert.Equal(t, []string{"https://pbs.twimg.com/media/1.jpg"}, tweet.Urls)
+
+	// the visibility wrappers are unwrapped however deep
+	tweet, err = parse(`{"__typename":"TweetWithVisibilityResults","tweet":{"__typename":"TweetWithVisibilityResults","tweet":` + tweetResult("2", testCreatedAt) + `}}`)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), tweet.Id)
+
+	// the tweets that cannot be seen are kept as their ids and why
+	tests := []struct {
+		result      string
+		id          uint64
+		unavailable string
+	}{
+		{`{"__typename":"TweetTombstone","tombstone":{}}`, 0, "TweetTombstone"},
+		{`{"__typename":"TweetUnavailable","rest_id":"3"}`, 3, "TweetUnavailable"},
+		{`{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"4","core":{}}}`, 4, "tweet without legacy"},
+	}
+	for _, tt := range tests {
+		tweet, err = parse(tt.result)
+		require.NoError(t, err)
+		assert.Equal(t, tt.id, tweet.Id)
+		assert.Equal(t, tt.unavailable, tweet.unavailable)
+	}
+
+	before := SkippedEntries()
+	tweet, err = parse(`null`)
+	require.NoError(t, err)
+	assert.Nil(t, tweet)
+	assert.Equal(t, map[string]int32{"tweet without result": 1}, skippedSince(before))
+}
+
+func TestItemContentsToTweetsUnavailable(t *testing.T) {
+	before := SkippedEntries()
+	items, _, err := parseTimelinePage(userMediaPage(addEntries(
+		tweetEntry("tweet-1790000000000000381", `{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"1790000000000000381"}}`),
+		tweetEntry("profile-grid-0-tweet-1790000000000000371", `{"__typename":"TweetTombstone"}`),
+		tweetEntry("who-knows", `{"__typename":"TweetTombstone"}`),
+		testBottomCursor,
+	)), INST_PATH_USER_MEDIA)
+	require.NoError(t, err)
+
+	tweets, err := itemContentsToTweets(items)
+	require.NoError(t, err)
+	require.Len(t, tweets, 2)
+	assert.Equal(t, uint64(1790000000000000381), tweets[0].Id)
+	assert.Equal(t, uint64(1790000000000000371), tweets[1].Id)
+	// the ids tell when the tweets were created
+	assert.Equal(t, 2024, tweets[0].CreatedAt.Year())
+	assert.Equal(t, map[string]int32{"TweetTombstone": 1}, skippedSince(before))
+}
+
+func TestParseTweetResultsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		path   string
+	}{
+		{"invalid created_at", tweetResult("1", "2024-05-15"), "tweet_results.result.legacy.created_at"},
+		{"wrapped invalid created_at", `{"__typename":"TweetWithVisibilityResults","tweet":` + tweetResult("1", "") + `}`, "tweet_results.result.tweet.legacy.created_at"},
+		{"invalid id", tweetResult("abc", testCreatedAt), "tweet_results.result.rest_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tweetResults := gjson.Parse(`{"result":` + tt.result + `}`)
+			_, err := parseTweetResults(&tweetResults, "tweet_results")
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.path, parseErr.Path)
+		})
+	}
+}
+
+func TestItemContentsToTweetsError(t *testing.T) {
+	items, _, err := parseTimelinePage(userMediaPage(addEntries(
+		tweetEntry("tweet-1", tweetResult("1", testCreatedAt)),
+		tweetEntry("tweet-2", tweetResult("2", "now")),
+		testBottomCursor,
+	)), INST_PATH_USER_MEDIA)
+	require.NoError(t, err)
+
+	_, err = itemContentsToTweets(items)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, INST_PATH_USER_MEDIA+".0.entries.1.content.itemContent.tweet_results.result.legacy.created_at", parseErr.Path)
+	assert.Equal(t, `"now"`, parseErr.Value)
+}
@@ -2,6 +2,7 @@ package twitterclient
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/tidwall/gjson"
@@ -17,12 +18,12 @@ const (
 ////////////////////////////////////////////////////////////////////////////////
 
 // itemContentsToUsers converts timeline item contents to User objects
-func itemContentsToUsers(itemContents []gjson.Result) []*User {
+func itemContentsToUsers(itemContents []timelineItem) []*User {
 	logger := log.WithField("caller", "Client.itemContentsToUsers")
 
 	users := make([]*User, 0, len(itemContents))
 	for _, ic := range itemContents {
-		user_results := getResults(ic, timelineUser)
+		user_results := getResults(ic.content, timelineUser)
 		if user_results.String() == "{}" {
 			continue
 		}
@@ -105,5 +106,25 @@ func parseUserJson(userJson *gjson.Result) (*User, error) {
 	usr.MediaCount = int(media_count.Int())
 	usr.Muting = muting.Exists() && muting.Bool()
 	usr.Blocking = blocking.Exists() && blocking.Bool()
+
+	usr.Description = legacy.Get("description").String()
+	usr.Location = legacy.Get("location").String()
+	usr.Url = getUserUrl(&legacy)
+	usr.ProfileImageUrl = legacy.Get("profile_image_url_https").String()
+	usr.ProfileBannerUrl = legacy.Get("profile_banner_url").String()
+	usr.FollowersCount = int(legacy.Get("followers_count").Int())
+	usr.IsVerified = legacy.Get("verified").Bool() || result.Get("is_blue_verified").Bool()
+	if createdAt, err := time.Parse(time.RubyDate, legacy.Get("created_at").String()); err == nil {
+		usr.CreatedAt = createdAt
+	}
 	return &usr, nil
 }
+
+// getUserUrl returns the expanded profile website, falling back to the t.co link
+func getUserUrl(legacy *gjson.Result) string {
+	expanded := legacy.Get("entities.url.urls.0.expanded_url")
+	if expanded.Exists() && expanded.String() != "" {
+		return expanded.String()
+	}
+	return legacy.Get("url").String()
+}
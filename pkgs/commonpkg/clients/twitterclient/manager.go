@@ -23,6 +23,9 @@ type Manager struct {
 
 	clientRateLimiters *utils.SyncMap[*Client, *rateLimitManager] // tracks client rate limit
 	apiCounts          *utils.SyncMap[string, *atomic.Int32]      // tracks API call counts
+
+	budgets       *Budgets
+	clientBudgets *utils.SyncMap[*Client, *budgetTracker] // tracks requests against the budgets
 }
 
 func NewManager() *Manager {
@@ -35,6 +38,8 @@ func NewManager() *Manager {
 
 		clientRateLimiters: utils.NewSyncMap[*Client, *rateLimitManager](),
 		apiCounts:          utils.NewSyncMap[string, *atomic.Int32](),
+
+		clientBudgets: utils.NewSyncMap[*Client, *budgetTracker](),
 	}
 }
 
@@ -54,9 +59,11 @@ func (m *Manager) AddClient(client *Client) error {
 		return nil
 	}
 
+	tracker := m.trackBudget(client)
 	client.SetRequestCounting(func(path string) {
 		count, _ := m.apiCounts.LoadOrStore(path, &atomic.Int32{})
 		count.Add(1)
+		tracker.count(path, time.Now())
 	})
 
 	name, err := client.GetScreenName(ctx)
@@ -65,6 +72,7 @@ func (m *Manager) AddClient(client *Client) error {
 		return fmt.Errorf("failed to get screen name for client: %w", err)
 	}
 	m.clientScreenNames.Store(client, name)
+	tracker.setBudget(m.budgets.BudgetOf(name, client == m.masterClient))
 
 	m.clients = append(m.clients, client)
 	return nil
@@ -104,10 +112,11 @@ func (m *Manager) GetAvailableClients() []*Client {
 	return available
 }
 
-// SelectClient selects an available client that won't block for the given path
+// SelectClient selects an available client that won't block for the given path,
+// waiting for the quarantined ones too
 func (m *Manager) SelectClient(ctx context.Context, path string) *Client {
 	for ctx.Err() == nil {
-		clients := m.GetAvailableClients()
+		clients := m.GetClients()
 		errorCount := 0
 
 		for _, client := range clients {
@@ -116,7 +125,7 @@ func (m *Manager) SelectClient(ctx context.Context, path string) *Client {
 				continue
 			}
 
-			if !client.WouldBlock(path) {
+			if client.IsAvailable() && m.AvailableAt(client, path).IsZero() {
 				return client
 			}
 		}
@@ -150,12 +159,12 @@ func (m *Manager) SelectClient(ctx context.Context, path string) *Client {
 
 // SelectClientForMediaRequest selects a client suitable for user media requests
 func (m *Manager) SelectClientForMediaRequest(ctx context.Context) *Client {
-	return m.SelectClient(ctx, "/i/api/graphql/MOLbHrtk8Ovu7DUNOLcXiA/UserMedia")
+	return m.SelectClient(ctx, GraphQLPath(GRAPHQL_USER_MEDIA))
 }
 
 // SelectClientForUserRequest selects a client suitable for user information requests
 func (m *Manager) SelectClientForUserRequest(ctx context.Context) *Client {
-	return m.SelectClient(ctx, "/i/api/graphql/xmU6X_CKVnQ5lSrCbAmJsg/UserByScreenName")
+	return m.SelectClient(ctx, GraphQLPath(GRAPHQL_USER_BY_SCREEN_NAME))
 }
 
 // GetClientCount returns the number of clients in the manager
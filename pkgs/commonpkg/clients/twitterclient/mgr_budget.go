@@ -0,0 +1,72 @@
+package twitterclient
+
+import "time"
+
+// ClientUsage is the consumption of an account, for the reports
+type ClientUsage struct {
+	ScreenName string
+	Budget     *Budget
+	Usage      []EndpointUsage
+}
+
+// SetBudgets caps the requests of the accounts, the ones already signed in
+// included. SelectClient skips an account once it has spent its budget of an
+// endpoint until the hour or the day is over.
+func (m *Manager) SetBudgets(budgets *Budgets) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.budgets = budgets
+	for _, item := range m.clientBudgets.Range() {
+		name, _ := m.clientScreenNames.Load(item.Key)
+		item.Value.setBudget(budgets.BudgetOf(name, item.Key == m.masterClient))
+	}
+}
+
+// AvailableAt returns when the client can request path again, the latest of
+// the reset of its quota, the end of its quarantine and the renewal of its
+// budget. It is the zero time when a request would go through now.
+func (m *Manager) AvailableAt(client *Client, path string) time.Time {
+	at := client.AvailableAt(path)
+	if tracker, ok := m.clientBudgets.Load(client); ok {
+		if until := tracker.exhaustedUntil(path, time.Now()); until.After(at) {
+			at = until
+		}
+	}
+	return at
+}
+
+// GetUsage returns the requests of every client by endpoint in the current
+// hour and day
+func (m *Manager) GetUsage() []ClientUsage {
+	now := time.Now()
+	res := make([]ClientUsage, 0)
+	for _, client := range m.GetClients() {
+		tracker, ok := m.clientBudgets.Load(client)
+		if !ok {
+			continue
+		}
+		name, _ := m.clientScreenNames.Load(client)
+		res = append(res, ClientUsage{
+			ScreenName: name,
+			Budget:     tracker.getBudget(),
+			Usage:      tracker.snapshot(now),
+		})
+	}
+	return res
+}
+
+// trackBudget counts the requests of a client newly added, restoring the
+// counts of the previous runs
+func (m *Manager) trackBudget(client *Client) *budgetTracker {
+	tracker := newBudgetTracker(nil)
+	if store := getRateLimitStore(); store != nil {
+		account := AccountKey(client.authToken)
+		tracker.restore(store.LoadUsage(account), time.Now())
+		tracker.onUpdate = func(usage []EndpointUsage) {
+			store.SaveUsage(account, usage)
+		}
+	}
+	m.clientBudgets.Store(client, tracker)
+	return tracker
+}
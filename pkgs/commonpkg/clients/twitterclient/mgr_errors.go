@@ -1,6 +1,10 @@
 package twitterclient
 
-import log "github.com/sirupsen/logrus"
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
 
 func (m *Manager) SetClientError(client *Client, err error) {
 	m.mutex.Lock()
@@ -14,3 +18,13 @@ func (m *Manager) SetClientError(client *Client, err error) {
 	}
 	m.clientErrors.Store(client, err)
 }
+
+// QuarantineClient takes a client out of the rotation until the given time.
+// Unlike SetClientError the client comes back on its own once it has cooled
+// off.
+func (m *Manager) QuarantineClient(client *Client, until time.Time, reason string) {
+	log.
+		WithFields(log.Fields{"caller": "Manager.QuarantineClient", "client": client.screenName}).
+		Debugln("quarantining client:", reason)
+	client.Quarantine(until, reason)
+}
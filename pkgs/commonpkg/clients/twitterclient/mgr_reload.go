@@ -0,0 +1,77 @@
+package twitterclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RetireClient stops selecting a client. Requests already made with it are
+// left to finish, it is only marked unavailable and dropped from the pool.
+func (m *Manager) RetireClient(client *Client) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if client == m.masterClient {
+		return fmt.Errorf("cannot retire the master client")
+	}
+
+	client.SetError(ErrClientRetired)
+	m.clientErrors.Store(client, ErrClientRetired)
+	for i, c := range m.clients {
+		if c == client {
+			m.clients = append(m.clients[:i], m.clients[i+1:]...)
+			break
+		}
+	}
+	m.clientScreenNames.Delete(client)
+	m.clientBudgets.Delete(client)
+	return nil
+}
+
+// WatchFile calls reload whenever the file at path changes, which is polled
+// every interval, and whenever trigger receives. It blocks until ctx is done.
+func (m *Manager) WatchFile(ctx context.Context, path string, interval time.Duration, trigger <-chan os.Signal, reload func(ctx context.Context) error) {
+	logger := log.WithFields(log.Fields{"caller": "Manager.WatchFile", "path": path})
+
+	last := statFile(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-trigger:
+			logger.Infoln("caught signal, reloading:", sig)
+		case <-ticker.C:
+			current := statFile(path)
+			if current == last {
+				continue
+			}
+			logger.Infoln("file changed, reloading")
+		}
+
+		last = statFile(path)
+		if err := reload(ctx); err != nil {
+			logger.Warnln("failed to reload:", err)
+		}
+	}
+}
+
+type fileStat struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func statFile(path string) fileStat {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
@@ -0,0 +1,77 @@
+package twitterclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSignedInClient(authToken string, screenName string) *Client {
+	client := New(authToken, "ct0")
+	client.screenName = screenName
+	return client
+}
+
+func TestManagerRetireClient(t *testing.T) {
+	manager := NewManager()
+	master := newSignedInClient("master", "master")
+	other := newSignedInClient("other", "other")
+	manager.SetMasterClient(master)
+	require.NoError(t, manager.AddClient(master))
+	require.NoError(t, manager.AddClient(other))
+
+	require.NoError(t, manager.RetireClient(other))
+
+	assert.Equal(t, []*Client{master}, manager.GetClients())
+	assert.False(t, other.IsAvailable())
+	assert.ErrorIs(t, other.GetError(), ErrClientRetired)
+	assert.Same(t, master, manager.SelectClient(context.Background(), "/path"))
+	assert.Error(t, manager.RetireClient(master))
+
+	// the same account can come back with a new client
+	again := newSignedInClient("other", "other")
+	require.NoError(t, manager.AddClient(again))
+	assert.Equal(t, []*Client{master, again}, manager.GetClients())
+}
+
+func TestManagerWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.yaml")
+	trigger := make(chan os.Signal, 1)
+	reloads := make(chan struct{}, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		NewManager().WatchFile(ctx, path, 10*time.Millisecond, trigger, func(ctx context.Context) error {
+			reloads <- struct{}{}
+			return nil
+		})
+	}()
+
+	waitReload := func() bool {
+		select {
+		case <-reloads:
+			return true
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	trigger <- os.Interrupt
+	assert.True(t, waitReload(), "the trigger reloads")
+
+	require.NoError(t, os.WriteFile(path, []byte("- auth_token: a\n"), 0600))
+	assert.True(t, waitReload(), "creating the file reloads")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, reloads, "an unchanged file does not reload")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,33 @@
+package twitterclient
+
+import "time"
+
+// TrySelectClient returns an available client that would not block on path,
+// or nil right away when every one of them would
+func (m *Manager) TrySelectClient(path string) *Client {
+	for _, client := range m.GetAvailableClients() {
+		if m.AvailableAt(client, path).IsZero() {
+			return client
+		}
+	}
+	return nil
+}
+
+// NextAvailableAt returns when the first client gets its quota or budget of
+// path back or leaves its quarantine, the zero time when one would not block now. It
+// returns false when every client is in error.
+func (m *Manager) NextAvailableAt(path string) (time.Time, bool) {
+	var earliest time.Time
+	found := false
+	for _, client := range m.GetClients() {
+		if client.GetError() != nil {
+			continue
+		}
+		at := m.AvailableAt(client, path)
+		if !found || at.Before(earliest) {
+			earliest = at
+		}
+		found = true
+	}
+	return earliest, found
+}
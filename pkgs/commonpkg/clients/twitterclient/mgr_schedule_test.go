@@ -0,0 +1,56 @@
+package twitterclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exhaust(client *Client, path string, reset time.Time) {
+	client.rateLimiter.restore([]RateLimit{{Path: path, Limit: 500, Remaining: 0, ResetTime: reset}})
+}
+
+func TestManagerRateWindows(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	manager := NewManager()
+	first := newSignedInClient("first", "first")
+	second := newSignedInClient("second", "second")
+	require.NoError(t, manager.AddClient(first))
+	require.NoError(t, manager.AddClient(second))
+
+	// nothing known yet, any client goes
+	assert.Same(t, first, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+	at, ok := manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.True(t, ok)
+	assert.True(t, at.IsZero())
+
+	// the first window is spent, the second client takes over
+	exhaust(first, GRAPHQL_USER_MEDIA, now.Add(10*time.Minute))
+	assert.Same(t, second, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+	at, _ = manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.True(t, at.IsZero())
+
+	// both spent, the earliest reset wins whatever the order of the clients
+	exhaust(second, GRAPHQL_USER_MEDIA, now.Add(5*time.Minute))
+	assert.Nil(t, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+	at, ok = manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(5*time.Minute), at)
+	assert.Equal(t, now.Add(10*time.Minute), first.AvailableAt(GRAPHQL_USER_MEDIA))
+
+	// the windows are per path
+	assert.Same(t, first, manager.TrySelectClient(GRAPHQL_USER_BY_REST_ID))
+
+	// a client in error is not waited for
+	second.SetError(errors.New("account is locked"))
+	at, _ = manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.Equal(t, now.Add(10*time.Minute), at)
+
+	first.SetError(errors.New("account is locked"))
+	_, ok = manager.NextAvailableAt(GRAPHQL_USER_MEDIA)
+	assert.False(t, ok)
+	assert.Nil(t, manager.TrySelectClient(GRAPHQL_USER_MEDIA))
+}
@@ -0,0 +1,225 @@
+package twitterclient
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// RateLimitStore keeps the quotas of every account between runs, so the first
+// requests after a restart respect what the previous run used up
+type RateLimitStore interface {
+	Load(account string) []RateLimit
+	Save(account string, limits []RateLimit)
+	SetScreenName(account string, screenName string)
+
+	// LoadUsage and SaveUsage keep the requests counted against the budget
+	// of the account
+	LoadUsage(account string) []EndpointUsage
+	SaveUsage(account string, usage []EndpointUsage)
+}
+
+var (
+	rateLimitStore      RateLimitStore
+	rateLimitStoreMutex sync.RWMutex
+)
+
+// SetRateLimitStore makes every client created afterwards restore its quotas
+// from the store and save them there
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStoreMutex.Lock()
+	defer rateLimitStoreMutex.Unlock()
+	rateLimitStore = store
+}
+
+func getRateLimitStore() RateLimitStore {
+	rateLimitStoreMutex.RLock()
+	defer rateLimitStoreMutex.RUnlock()
+	return rateLimitStore
+}
+
+// AccountKey identifies an account in the store without keeping its token
+func AccountKey(authToken string) string {
+	sum := sha256.Sum256([]byte(authToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// FILE_RATE_LIMIT_STORE_DELAY is the least time between two writes of the file,
+// Flush writes what is left
+const FILE_RATE_LIMIT_STORE_DELAY = 5 * time.Second
+
+// AccountRateLimits are the stored quotas of an account
+type AccountRateLimits struct {
+	Account    string      `json:"account"`
+	ScreenName string      `json:"screen_name"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Limits     []RateLimit `json:"limits"`
+
+	Usage []EndpointUsage `json:"usage,omitempty"`
+}
+
+type fileRateLimitStore struct {
+	path      string
+	mutex     sync.Mutex
+	accounts  map[string]*AccountRateLimits
+	dirty     bool
+	lastWrite time.Time
+}
+
+// NewFileRateLimitStore reads the quotas saved at path, a missing file is an
+// empty store
+func NewFileRateLimitStore(path string) (*fileRateLimitStore, error) {
+	s := &fileRateLimitStore{
+		path:     path,
+		accounts: make(map[string]*AccountRateLimits),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []*AccountRateLimits
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		s.accounts[account.Account] = account
+	}
+	return s, nil
+}
+
+func (s *fileRateLimitStore) Load(account string) []RateLimit {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if stored, ok := s.accounts[account]; ok {
+		return append([]RateLimit(nil), stored.Limits...)
+	}
+	return nil
+}
+
+func (s *fileRateLimitStore) Save(account string, limits []RateLimit) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := s.getOrCreate(account)
+	stored.Limits = limits
+	stored.UpdatedAt = time.Now()
+	s.dirty = true
+	if time.Since(s.lastWrite) >= FILE_RATE_LIMIT_STORE_DELAY {
+		s.write()
+	}
+}
+
+func (s *fileRateLimitStore) LoadUsage(account string) []EndpointUsage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if stored, ok := s.accounts[account]; ok {
+		return append([]EndpointUsage(nil), stored.Usage...)
+	}
+	return nil
+}
+
+func (s *fileRateLimitStore) SaveUsage(account string, usage []EndpointUsage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := s.getOrCreate(account)
+	stored.Usage = usage
+	s.dirty = true
+	if time.Since(s.lastWrite) >= FILE_RATE_LIMIT_STORE_DELAY {
+		s.write()
+	}
+}
+
+func (s *fileRateLimitStore) SetScreenName(account string, screenName string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := s.getOrCreate(account)
+	if stored.ScreenName != screenName {
+		stored.ScreenName = screenName
+		s.dirty = true
+	}
+}
+
+// Flush writes the quotas saved since the last write
+func (s *fileRateLimitStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	return s.write()
+}
+
+// Accounts returns every stored account, sorted by screen name
+func (s *fileRateLimitStore) Accounts() []*AccountRateLimits {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := make([]*AccountRateLimits, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		copied := *account
+		copied.Limits = append([]RateLimit(nil), account.Limits...)
+		copied.Usage = append([]EndpointUsage(nil), account.Usage...)
+		res = append(res, &copied)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ScreenName != res[j].ScreenName {
+			return res[i].ScreenName < res[j].ScreenName
+		}
+		return res[i].Account < res[j].Account
+	})
+	return res
+}
+
+func (s *fileRateLimitStore) getOrCreate(account string) *AccountRateLimits {
+	stored, ok := s.accounts[account]
+	if !ok {
+		stored = &AccountRateLimits{Account: account}
+		s.accounts[account] = stored
+	}
+	return stored
+}
+
+// write replaces the file through a temporary one, so a crash never leaves
+// it half written
+func (s *fileRateLimitStore) write() error {
+	accounts := make([]*AccountRateLimits, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Account < accounts[j].Account })
+
+	data, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	s.lastWrite = time.Now()
+	return nil
+}
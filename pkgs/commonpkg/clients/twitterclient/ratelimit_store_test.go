@@ -0,0 +1,89 @@
+package twitterclient
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRateLimitStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rate_limits.json")
+	resetTime := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	limits := []RateLimit{{Path: "/p", Limit: 500, Remaining: 12, ResetTime: resetTime}}
+
+	store, err := NewFileRateLimitStore(path)
+	require.NoError(t, err)
+	store.SetScreenName("account", "alice")
+	store.Save("account", limits)
+	require.NoError(t, store.Flush())
+
+	reopened, err := NewFileRateLimitStore(path)
+	require.NoError(t, err)
+	loaded := reopened.Load("account")
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "/p", loaded[0].Path)
+	assert.Equal(t, 12, loaded[0].Remaining)
+	assert.True(t, resetTime.Equal(loaded[0].ResetTime))
+
+	accounts := reopened.Accounts()
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "alice", accounts[0].ScreenName)
+	assert.Nil(t, reopened.Load("other"))
+}
+
+func TestClientRestoresRateLimits(t *testing.T) {
+	store, err := NewFileRateLimitStore(filepath.Join(t.TempDir(), "rate_limits.json"))
+	require.NoError(t, err)
+	store.Save(AccountKey("token"), []RateLimit{
+		{Path: "/exhausted", Limit: 500, Remaining: 1, ResetTime: time.Now().Add(10 * time.Minute)},
+		{Path: "/expired", Limit: 500, Remaining: 0, ResetTime: time.Now().Add(-time.Minute)},
+		{Path: "/available", Limit: 500, Remaining: 400, ResetTime: time.Now().Add(10 * time.Minute)},
+	})
+
+	SetRateLimitStore(store)
+	t.Cleanup(func() { SetRateLimitStore(nil) })
+
+	client := New("token", "ct0")
+	assert.True(t, client.WouldBlock("/exhausted"))
+	assert.False(t, client.WouldBlock("/expired"))
+	assert.False(t, client.WouldBlock("/available"))
+	assert.Len(t, client.RateLimits(), 2)
+
+	// another account does not share the quotas
+	assert.False(t, New("other", "ct0").WouldBlock("/exhausted"))
+}
+
+func TestRateLimitsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rate_limits.json")
+	store, err := NewFileRateLimitStore(path)
+	require.NoError(t, err)
+	SetRateLimitStore(store)
+	t.Cleanup(func() { SetRateLimitStore(nil) })
+
+	client, server := newFakeXClient(t)
+	server.SetRateLimit("UserByRestId", 3)
+	userByRestId := GraphQLPath(GRAPHQL_USER_BY_REST_ID)
+
+	// the second request uses up the quota without a new window to read
+	for i := 0; i < 2; i++ {
+		_, err := client.GetUserById(ctx, 1001)
+		require.NoError(t, err)
+	}
+	require.True(t, client.WouldBlock(userByRestId))
+	require.NoError(t, store.Flush())
+
+	reopened, err := NewFileRateLimitStore(path)
+	require.NoError(t, err)
+	SetRateLimitStore(reopened)
+	restarted, err := NewWithOptions("token", "ct0", Options{ApiHost: server.URL, MediaHost: server.URL})
+	require.NoError(t, err)
+	assert.True(t, restarted.WouldBlock(userByRestId))
+	_, err = restarted.GetUserById(ctx, 1001)
+	assert.ErrorContains(t, err, ErrWouldBlock.Error())
+	assert.Equal(t, 2, server.Count("UserByRestId"))
+}
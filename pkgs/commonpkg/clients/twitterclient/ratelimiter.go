@@ -4,8 +4,8 @@ import (
 	"context"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -21,6 +21,9 @@ type rateLimitManager struct {
 	pathLimitsMap *utils.SyncMap[string, *xRateLimit]
 	pathCondsMap  *utils.SyncMap[string, *sync.Cond]
 	nonBlocking   bool
+
+	// onUpdate is called whenever a response or a request updates a limit
+	onUpdate func()
 }
 
 // newRateLimiter creates a new rate limiter
@@ -67,8 +70,16 @@ func (rlMgr *rateLimitManager) check(ctx context.Context, url *url.URL) error {
 	}
 
 	// limiter 为 nil 意味着不对此路径做速率限制
-	if pathLimit != nil {
-		return pathLimit.safePreRequest(ctx, rlMgr.nonBlocking)
+	if pathLimit == nil {
+		return nil
+	}
+	if err := pathLimit.safePreRequest(ctx, rlMgr.nonBlocking); err != nil {
+		return err
+	}
+	// the responses of a ready limit are not read again, so the requests
+	// counted here are what a restart has to know about
+	if rlMgr.onUpdate != nil {
+		rlMgr.onUpdate()
 	}
 	return nil
 }
@@ -106,11 +117,33 @@ func (rlMgr *rateLimitManager) reset(url *url.URL, resp *resty.Response) {
 	rateLimit := newRateLimit(resp)
 	rlMgr.pathLimitsMap.Store(path, rateLimit)
 	pathCond.Broadcast()
+
+	if rateLimit != nil && rlMgr.onUpdate != nil {
+		rlMgr.onUpdate()
+	}
+}
+
+// restore loads limits saved by a previous run, the expired ones are dropped
+// since the first request refreshes them anyway
+func (rlMgr *rateLimitManager) restore(limits []RateLimit) {
+	now := time.Now()
+	for _, limit := range limits {
+		if !now.Before(limit.ResetTime) {
+			continue
+		}
+		rlMgr.pathLimitsMap.Store(limit.Path, &xRateLimit{
+			ResetTime: limit.ResetTime,
+			Remaining: limit.Remaining,
+			Limit:     limit.Limit,
+			Ready:     true,
+			Url:       limit.Path,
+		})
+	}
 }
 
 // shouldWork determines if rate limiting should be applied to the given URL
 func (rlMgr *rateLimitManager) shouldWork(url *url.URL) bool {
-	return !strings.HasSuffix(url.Host, "twimg.com")
+	return !isMediaHost(url.Hostname())
 }
 
 // wouldBlock checks if a request to the given path would block due to rate limiting
@@ -121,6 +154,61 @@ func (rlMgr *rateLimitManager) wouldBlock(path string) bool {
 	return false
 }
 
+// availableAt returns the reset time of path when a request to it would
+// block, the zero time otherwise
+func (rlMgr *rateLimitManager) availableAt(path string) time.Time {
+	pathLimit, ok := rlMgr.pathLimitsMap.Load(path)
+	if !ok || pathLimit == nil {
+		return time.Time{}
+	}
+	pathLimit.Mtx.Lock()
+	defer pathLimit.Mtx.Unlock()
+	if pathLimit.wouldBlock() {
+		return pathLimit.ResetTime
+	}
+	return time.Time{}
+}
+
+// snapshot returns the known limits of every path, sorted by path
+func (rlMgr *rateLimitManager) snapshot() []RateLimit {
+	res := make([]RateLimit, 0)
+	for _, item := range rlMgr.pathLimitsMap.Range() {
+		if item.Value == nil {
+			continue
+		}
+		item.Value.Mtx.Lock()
+		if item.Value.Ready {
+			res = append(res, RateLimit{
+				Path:      item.Key,
+				Limit:     item.Value.Limit,
+				Remaining: item.Value.Remaining,
+				ResetTime: item.Value.ResetTime,
+			})
+		}
+		item.Value.Mtx.Unlock()
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// RateLimit is the quota of an endpoint as last reported by Twitter
+type RateLimit struct {
+	Path      string    `json:"path"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetTime time.Time `json:"reset_time"`
+}
+
+// Headroom is the share of the quota left, a reset quota is fully available
+func (rl RateLimit) Headroom(now time.Time) float64 {
+	if rl.Limit <= 0 || now.After(rl.ResetTime) {
+		return 1
+	}
+	return float64(rl.Remaining) / float64(rl.Limit)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 // xRateLimit represents Twitter API rate limit information for a specific endpoint
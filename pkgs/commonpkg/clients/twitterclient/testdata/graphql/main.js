@@ -0,0 +1,11 @@
+/*! main bundle of the web client, trimmed to the modules declaring GraphQL operations */
+(self.webpackChunk_twitter_responsive_web=self.webpackChunk_twitter_responsive_web||[]).push([["main"],{
+81234:e=>{e.exports={queryId:"1VOOyvKkiI3FMmkeDNxM9A",operationName:"UserByRestId",operationType:"query",metadata:{featureSwitches:["hidden_profile_subscriptions_enabled","profile_label_improvements_pcf_label_in_post_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","highlights_tweets_tab_ui_enabled","responsive_web_twitter_article_notes_tab_enabled","subscriptions_feature_can_gift_premium","creator_subscriptions_tweet_preview_api_enabled","responsive_web_graphql_skip_user_profile_image_extensions_enabled","responsive_web_graphql_timeline_navigation_enabled"],fieldToggles:["withAuxiliaryUserLabels"]}}},
+81235:e=>{e.exports={queryId:"32pL5BWe9WKeSK1MoPvFQQ",operationName:"UserByScreenName",operationType:"query",metadata:{featureSwitches:["hidden_profile_subscriptions_enabled","profile_label_improvements_pcf_label_in_post_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","subscriptions_verification_info_is_identity_verified_enabled","subscriptions_verification_info_verified_since_enabled","highlights_tweets_tab_ui_enabled","responsive_web_twitter_article_notes_tab_enabled","subscriptions_feature_can_gift_premium","creator_subscriptions_tweet_preview_api_enabled","responsive_web_graphql_skip_user_profile_image_extensions_enabled","responsive_web_graphql_timeline_navigation_enabled"],fieldToggles:["withAuxiliaryUserLabels"]}}},
+81236:e=>{e.exports={queryId:"HaouMjBviBKKTYZGV_9qtg",operationName:"UserMedia",operationType:"query",metadata:{featureSwitches:["rweb_video_screen_enabled","profile_label_improvements_pcf_label_in_post_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","creator_subscriptions_tweet_preview_api_enabled","responsive_web_graphql_timeline_navigation_enabled","responsive_web_graphql_skip_user_profile_image_extensions_enabled","premium_content_api_read_enabled","communities_web_enable_tweet_community_results_fetch","c9s_tweet_anatomy_moderator_badge_enabled","responsive_web_grok_analyze_button_fetch_trends_enabled","articles_preview_enabled","responsive_web_edit_tweet_api_enabled","graphql_is_translatable_rweb_tweet_is_translatable_enabled","view_counts_everywhere_api_enabled","longform_notetweets_consumption_enabled","responsive_web_twitter_article_tweet_consumption_enabled","tweet_awards_web_tipping_enabled","freedom_of_speech_not_reach_fetch_enabled","standardized_nudges_misinfo","tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled","longform_notetweets_rich_text_read_enabled","longform_notetweets_inline_media_enabled","responsive_web_enhance_cards_enabled"],fieldToggles:["withArticlePlainText"]}}},
+81237:e=>{e.exports={queryId:"o5eNLkJb03ayTQa97Cpp7w",operationName:"Following",operationType:"query",metadata:{featureSwitches:["rweb_video_screen_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","creator_subscriptions_tweet_preview_api_enabled","responsive_web_graphql_timeline_navigation_enabled","responsive_web_graphql_skip_user_profile_image_extensions_enabled"],fieldToggles:[]}}},
+81238:e=>{e.exports={queryId:"Ktyc1sKkDVeZLZ6MjbDD-A",operationName:"ListMembers",operationType:"query",metadata:{featureSwitches:["rweb_video_screen_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","responsive_web_graphql_timeline_navigation_enabled"],fieldToggles:[]}}},
+81239:e=>{e.exports={queryId:"gO1_eYPohKYHwCG2m-1ZnQ",operationName:"ListByRestId",operationType:"query",metadata:{featureSwitches:["profile_label_improvements_pcf_label_in_post_enabled","rweb_tipjar_consumption_enabled","verified_phone_label_enabled","responsive_web_graphql_skip_user_profile_image_extensions_enabled","responsive_web_graphql_timeline_navigation_enabled"],fieldToggles:[]}}},
+81240:e=>{e.exports={queryId:"8r5oa_2vD0WkhIAOkY4TTA",operationName:"CreateBookmark",operationType:"mutation",metadata:{featureSwitches:[],fieldToggles:[]}}},
+90412:(e,t,n)=>{"use strict";n.d(t,{Z:()=>r});const r=n(81236)}
+}]);
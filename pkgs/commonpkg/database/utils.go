@@ -34,3 +34,18 @@ func ConnectDatabase(path string) (*sqlx.DB, error) {
 	}
 	return db, nil
 }
+
+// ConnectDatabaseReadOnly opens an existing database without creating or
+// migrating anything, for inspecting it
+func ConnectDatabaseReadOnly(path string) (*sqlx.DB, error) {
+	ex, err := utils.PathExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ex {
+		return nil, fmt.Errorf("database %s does not exist", path)
+	}
+
+	dsn := fmt.Sprintf("file:%s?mode=ro&busy_timeout=10000", path)
+	return sqlx.Connect("sqlite3", dsn)
+}
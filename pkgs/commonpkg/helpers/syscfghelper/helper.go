@@ -1,11 +1,14 @@
 package syscfghelper
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/logging"
 	"github.com/WangWilly/xSync/pkgs/downloading"
 )
@@ -13,17 +16,40 @@ import (
 type CliParams struct {
 	IsDebug       bool
 	ConfOverWrite bool
+
+	// ConfigPath and StateDir replace $HOME/.x_sync/conf.yaml and $HOME/.x_sync
+	ConfigPath string
+	StateDir   string
+	// Profile selects a named profile in the profiles dir of the state dir
+	Profile string
+	// Overrides are config values by key, applied over the file and the env
+	Overrides map[string]string
+	// NoValidate loads an incomplete config instead of exiting
+	NoValidate bool
+	// RecordDir saves the GraphQL traffic of the clients there, ReplayDir
+	// answers it from such a recording instead of x.com
+	RecordDir string
+	ReplayDir string
 }
 
 type helper struct {
 	cliParams CliParams
+	profile   string
 
 	logFile                 *os.File
 	clientLogFiles          []*os.File
+	clientLogMutex          sync.Mutex
 	workerClientLogFilePath string
 
 	sysConfig             *config.Config
+	sysConfigPath         string
 	additionalCookiesPath string
+	rateLimitsPath        string
+	graphqlCatalogPath    string
+	cipher                *config.Cipher
+
+	recorder *twitterclient.Recorder
+	replayer *twitterclient.Replayer
 }
 
 func New(cliParams CliParams) *helper {
@@ -37,7 +63,12 @@ func New(cliParams CliParams) *helper {
 }
 
 func (h *helper) init() {
-	sysStateDir := filepath.Join(getHomePath(), SYS_STATE_DIR)
+	if err := CheckProfile(h.cliParams); err != nil {
+		log.Fatalln(err)
+	}
+	h.profile = GetProfileName(h.cliParams)
+
+	sysStateDir, confPath := ResolvePaths(h.cliParams)
 	if err := os.MkdirAll(sysStateDir, 0755); err != nil {
 		log.Fatalln("failed to make app dir", err)
 	}
@@ -56,30 +87,126 @@ func (h *helper) init() {
 
 	////////////////////////////////////////////////////////////////////////////
 
-	confPath := filepath.Join(sysStateDir, SYS_CONF_FILE)
-	if ok, err := fileExists(confPath); err != nil {
-		log.Fatalln("failed to check config file existence:", err)
-	} else if !ok || h.cliParams.ConfOverWrite {
-		conf, err := config.PromptConfig(confPath)
-		if err != nil {
-			log.Fatalln("failed to prompt config:", err)
-		}
-		h.sysConfig = conf
-	} else {
-		conf, err := config.ParseConfigFromFile(confPath)
-		if err != nil {
-			log.Fatalln("failed to load config:", err)
+	h.sysConfigPath = confPath
+	conf, err := h.loadConfig(confPath)
+	if err != nil {
+		log.Fatalln("failed to load config:", err)
+	}
+	h.sysConfig = conf
+
+	// profiles sharing a root path would share the database and error backup
+	if !h.cliParams.NoValidate {
+		if err := CheckRootPathFree(h.cliParams, h.profile, conf.RootPath); err != nil {
+			log.Fatalln(err)
 		}
-		h.sysConfig = conf
 	}
 
 	h.additionalCookiesPath = filepath.Join(sysStateDir, ADDITIONAL_COOKIES_FILE)
+	h.rateLimitsPath = filepath.Join(sysStateDir, RATE_LIMITS_FILE)
+	h.graphqlCatalogPath = filepath.Join(sysStateDir, GRAPHQL_CATALOG_FILE)
 
 	////////////////////////////////////////////////////////////////////////////
+
+	if h.cliParams.RecordDir != "" && h.cliParams.ReplayDir != "" {
+		log.Fatalln("cannot record and replay at once")
+	}
+	if h.cliParams.RecordDir != "" {
+		if h.recorder, err = twitterclient.NewRecorder(h.cliParams.RecordDir); err != nil {
+			log.Fatalln("failed to open recording:", err)
+		}
+	}
+	if h.cliParams.ReplayDir != "" {
+		if h.replayer, err = twitterclient.NewReplayer(h.cliParams.ReplayDir); err != nil {
+			log.Fatalln("failed to load recording:", err)
+		}
+	}
+}
+
+// loadConfig reads the config file then applies the XSYNC_* variables and the
+// flag overrides. The wizard only runs when asked to, or when nothing is
+// configured at all and someone is at the terminal to answer it.
+func (h *helper) loadConfig(confPath string) (*config.Config, error) {
+	exists, err := fileExists(confPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check config file existence: %w", err)
+	}
+
+	if h.cliParams.ConfOverWrite {
+		return h.promptConfig(confPath, exists)
+	}
+
+	conf := &config.Config{}
+	if exists {
+		// the cookie is decrypted by the clients, only they need it
+		if conf, err = config.ParseConfigFromFile(confPath); err != nil {
+			return nil, err
+		}
+	}
+	if err := conf.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	for key, value := range h.cliParams.Overrides {
+		if err := conf.Set(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.cliParams.NoValidate {
+		return conf, nil
+	}
+	err = conf.Validate()
+	if err == nil {
+		return conf, nil
+	}
+	if !exists && *conf == (config.Config{}) && isInteractive() {
+		return config.PromptConfig(confPath, nil)
+	}
+	return nil, fmt.Errorf("invalid config %s:\n%w", confPath, err)
+}
+
+// promptConfig runs the wizard, keeping the cookie encrypted when it was. The
+// passphrase is checked against the old cookie before anything is asked.
+func (h *helper) promptConfig(confPath string, exists bool) (*config.Config, error) {
+	if !exists {
+		return config.PromptConfig(confPath, nil)
+	}
+	old, err := config.ParseConfigFromFile(confPath)
+	if err != nil || !old.Cookie.IsEncrypted() {
+		return config.PromptConfig(confPath, nil)
+	}
+
+	if err := h.decryptCookies(&old.Cookie); err != nil {
+		return nil, err
+	}
+	return config.PromptConfig(confPath, h.cipher)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
+func (h *helper) GetProfile() string {
+	return h.profile
+}
+
+func (h *helper) GetConfig() *config.Config {
+	return h.sysConfig
+}
+
+func (h *helper) GetConfigPath() string {
+	return h.sysConfigPath
+}
+
+// GetRateLimitsPath is the file keeping the quotas of every account between
+// runs
+func (h *helper) GetRateLimitsPath() string {
+	return h.rateLimitsPath
+}
+
+// GetGraphQLCatalogPath is the file caching the query ids discovered from the
+// web client
+func (h *helper) GetGraphQLCatalogPath() string {
+	return h.graphqlCatalogPath
+}
+
 func (h *helper) GetDownloadingCfg() downloading.Config {
 	return downloading.Config{
 		MaxDownloadRoutine: h.sysConfig.MaxDownloadRoutine,
@@ -3,6 +3,7 @@ package syscfghelper
 import (
 	"context"
 	"os"
+	"strings"
 
 	"github.com/WangWilly/xSync/pkgs/clipkg/config"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/clients/twitterclient"
@@ -14,10 +15,16 @@ import (
 func (h *helper) GetMainClient(ctx context.Context) (*twitterclient.Client, error) {
 	logger := log.WithField("caller", "syscfghelper.GetMainClient")
 
-	client := twitterclient.New(
-		h.sysConfig.Cookie.AuthToken,
-		h.sysConfig.Cookie.Ct0,
-	)
+	cookie, err := h.GetMainCookie()
+	if err != nil {
+		logger.Errorln("failed to decrypt cookie:", err)
+		return nil, err
+	}
+	client, err := h.NewClient(ctx, cookie)
+	if err != nil {
+		logger.Errorln("failed to create client:", err)
+		return nil, err
+	}
 	screenName, err := client.GetScreenName(ctx)
 	if err != nil {
 		logger.Errorln("failed to get screen name:", err)
@@ -26,17 +33,12 @@ func (h *helper) GetMainClient(ctx context.Context) (*twitterclient.Client, erro
 
 	////////////////////////////////////////////////////////////////////////////
 
-	clientLogFile, err := os.OpenFile(
-		h.workerClientLogFilePath,
-		os.O_TRUNC|os.O_WRONLY|os.O_CREATE,
-		0644,
-	)
+	clientLogFile, err := h.getClientLogFile()
 	if err != nil {
 		logger.Errorln("failed to create log file:", err)
 		return nil, err
 	}
 	twitterclient.SetTwitterClientLogger(client, clientLogFile)
-	h.clientLogFiles = append(h.clientLogFiles, clientLogFile)
 
 	////////////////////////////////////////////////////////////////////////////
 
@@ -47,30 +49,166 @@ func (h *helper) GetMainClient(ctx context.Context) (*twitterclient.Client, erro
 func (h *helper) GetOtherClients(ctx context.Context) ([]*twitterclient.Client, error) {
 	logger := log.WithField("caller", "syscfghelper.GetOtherClients")
 
-	cookies, err := config.ReadAdditionalCookies(h.additionalCookiesPath)
+	cookies, err := h.GetAdditionalCookies()
 	if err != nil {
 		logger.Warnln("failed to load additional cookies:", err)
 		return nil, err
 	}
 
-	clients := batchLogin(ctx, cookies)
+	return h.loginOtherClients(ctx, cookies)
+}
+
+// ReloadOtherClients signs in with the additional cookies the manager does not
+// have yet and retires the clients whose cookies were removed from the file
+func (h *helper) ReloadOtherClients(ctx context.Context, manager *twitterclient.Manager) error {
+	logger := log.WithField("caller", "syscfghelper.ReloadOtherClients")
+
+	cookies, err := h.GetAdditionalCookies()
+	if err != nil {
+		return err
+	}
+	configured := make(map[string]struct{}, len(cookies))
+	for _, cookie := range cookies {
+		configured[cookie.AuthToken] = struct{}{}
+	}
+
+	master := manager.GetMasterClient()
+	managed := make(map[string]struct{})
+	for _, client := range manager.GetClients() {
+		if client == master {
+			continue
+		}
+		if _, ok := configured[client.AuthToken()]; ok {
+			managed[client.AuthToken()] = struct{}{}
+			continue
+		}
+		name, _ := client.GetScreenName(ctx)
+		if err := manager.RetireClient(client); err != nil {
+			logger.Warnln("failed to retire client:", err)
+			continue
+		}
+		logger.Infoln("retired removed account:", name)
+	}
+
+	added := make([]*config.Cookie, 0)
+	for _, cookie := range cookies {
+		if _, ok := managed[cookie.AuthToken]; !ok {
+			added = append(added, cookie)
+		}
+	}
+	clients, err := h.loginOtherClients(ctx, added)
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		if err := manager.AddClient(client); err != nil {
+			logger.Warnln("failed to add additional client to manager:", err)
+		}
+	}
+	return nil
+}
+
+// GetAdditionalCookiesPath is the file of the additional cookies, watched for
+// changes during long runs
+func (h *helper) GetAdditionalCookiesPath() string {
+	return h.additionalCookiesPath
+}
+
+// GetMainCookie returns the cookie of the config, decrypted. Only this asks
+// for the passphrase, so the commands that sign in no account never do.
+func (h *helper) GetMainCookie() (*config.Cookie, error) {
+	if err := h.decryptCookies(&h.sysConfig.Cookie); err != nil {
+		return nil, err
+	}
+	return &h.sysConfig.Cookie, nil
+}
+
+// NewClient creates the client of an account behind its proxy, or the proxy
+// of the config, after checking that the proxies accept connections. It
+// talks to the api and media hosts of the config when they are set, and to
+// the recording instead when replaying. An encrypted cookie is decrypted.
+func (h *helper) NewClient(ctx context.Context, cookie *config.Cookie) (*twitterclient.Client, error) {
+	if err := h.decryptCookies(cookie); err != nil {
+		return nil, err
+	}
+	opts := twitterclient.Options{
+		ApiHost:   h.sysConfig.ApiHost,
+		MediaHost: h.sysConfig.MediaHost,
+		Recorder:  h.recorder,
+	}
+	if h.replayer != nil {
+		opts.Transport = h.replayer
+	}
+	client, err := twitterclient.NewWithOptions(cookie.AuthToken, cookie.Ct0, opts)
+	if err != nil {
+		return nil, err
+	}
+	if h.replayer != nil {
+		return client, nil
+	}
+
+	proxy := cookie.Proxy
+	if proxy == "" {
+		proxy = h.sysConfig.Proxy
+	}
+	if err := client.SetProxy(proxy, h.sysConfig.MediaProxy); err != nil {
+		return nil, err
+	}
+	if err := client.CheckProxy(ctx); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (h *helper) loginOtherClients(ctx context.Context, cookies []*config.Cookie) ([]*twitterclient.Client, error) {
+	clients := h.batchLogin(ctx, cookies)
+	clientLogFile, err := h.getClientLogFile()
+	if err != nil {
+		log.WithField("caller", "syscfghelper.loginOtherClients").Errorln("failed to create log file:", err)
+		return nil, err
+	}
+	for _, client := range clients {
+		twitterclient.SetTwitterClientLogger(client, clientLogFile)
+	}
+
+	return clients, nil
+}
+
+// getClientLogFile truncates the log of the clients once per run, every
+// client signed in later appends to it
+func (h *helper) getClientLogFile() (*os.File, error) {
+	h.clientLogMutex.Lock()
+	defer h.clientLogMutex.Unlock()
+
+	if len(h.clientLogFiles) > 0 {
+		return h.clientLogFiles[0], nil
+	}
 	clientLogFile, err := os.OpenFile(
 		h.workerClientLogFilePath,
 		os.O_TRUNC|os.O_WRONLY|os.O_CREATE,
 		0644,
 	)
 	if err != nil {
-		logger.Errorln("failed to create log file:", err)
 		return nil, err
 	}
-	for _, client := range clients {
-		twitterclient.SetTwitterClientLogger(client, clientLogFile)
-	}
+	h.clientLogFiles = append(h.clientLogFiles, clientLogFile)
+	return clientLogFile, nil
+}
 
-	return clients, nil
+// GetAdditionalCookies returns the decrypted additional cookies, without
+// signing in with them
+func (h *helper) GetAdditionalCookies() ([]*config.Cookie, error) {
+	cookies, err := config.ReadAdditionalCookies(h.additionalCookiesPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.decryptCookies(cookies...); err != nil {
+		return nil, err
+	}
+	return cookies, nil
 }
 
-func batchLogin(ctx context.Context, cookies []*config.Cookie) []*twitterclient.Client {
+func (h *helper) batchLogin(ctx context.Context, cookies []*config.Cookie) []*twitterclient.Client {
 	if len(cookies) == 0 {
 		return nil
 	}
@@ -82,7 +220,11 @@ func batchLogin(ctx context.Context, cookies []*config.Cookie) []*twitterclient.
 			continue
 		}
 
-		client := twitterclient.New(cookie.AuthToken, cookie.Ct0)
+		client, err := h.NewClient(ctx, cookie)
+		if err != nil {
+			log.Warnf("failed to create client %d: %v", i, err)
+			continue
+		}
 		screenName, err := client.GetScreenName(ctx)
 		if err != nil {
 			log.Warnf("failed to get screen name for client %d: %v", i, err)
@@ -96,3 +238,34 @@ func batchLogin(ctx context.Context, cookies []*config.Cookie) []*twitterclient.
 	log.Infoln("loaded additional accounts:", len(res))
 	return res
 }
+
+// GetBudgets returns the request budgets of the accounts set in the config
+func (h *helper) GetBudgets() *twitterclient.Budgets {
+	conf := h.sysConfig.Budgets
+	if conf == nil {
+		return nil
+	}
+
+	budgets := &twitterclient.Budgets{
+		Additional: toClientBudget(conf.Additional),
+		Accounts:   make(map[string]*twitterclient.Budget, len(conf.Accounts)),
+	}
+	for screenName, budget := range conf.Accounts {
+		budgets.Accounts[strings.TrimPrefix(screenName, "@")] = toClientBudget(budget)
+	}
+	return budgets
+}
+
+func toClientBudget(budget *config.Budget) *twitterclient.Budget {
+	if budget == nil {
+		return nil
+	}
+	res := &twitterclient.Budget{
+		Default:   twitterclient.BudgetLimits{PerHour: budget.PerHour, PerDay: budget.PerDay},
+		Endpoints: make(map[string]twitterclient.BudgetLimits, len(budget.Endpoints)),
+	}
+	for endpoint, limits := range budget.Endpoints {
+		res.Endpoints[endpoint] = twitterclient.BudgetLimits{PerHour: limits.PerHour, PerDay: limits.PerDay}
+	}
+	return res
+}
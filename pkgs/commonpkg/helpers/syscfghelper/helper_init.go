@@ -3,7 +3,10 @@ package syscfghelper
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"runtime"
+
+	"golang.org/x/term"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -14,10 +17,50 @@ const (
 	WORKER_CLIENT_LOG_FILE  = "worker_client.log"
 	SYS_CONF_FILE           = "conf.yaml"
 	ADDITIONAL_COOKIES_FILE = "additional_cookies.yaml"
+	RATE_LIMITS_FILE        = "rate_limits.json"
+	GRAPHQL_CATALOG_FILE    = "graphql.json"
+
+	STATE_DIR_ENV   = "XSYNC_STATE_DIR"
+	CONFIG_PATH_ENV = "XSYNC_CONFIG"
+	PROFILE_ENV     = "XSYNC_PROFILE"
 )
 
 ////////////////////////////////////////////////////////////////////////////////
 
+// ResolvePaths returns the state dir of the selected profile and its config
+// file path, from the params, then XSYNC_STATE_DIR, XSYNC_PROFILE and
+// XSYNC_CONFIG, then $HOME/.x_sync
+func ResolvePaths(cliParams CliParams) (string, string) {
+	sysStateDir := GetProfileStateDir(cliParams, GetProfileName(cliParams))
+
+	confPath := cliParams.ConfigPath
+	if confPath == "" {
+		confPath = os.Getenv(CONFIG_PATH_ENV)
+	}
+	if confPath == "" {
+		confPath = filepath.Join(sysStateDir, SYS_CONF_FILE)
+	}
+
+	return sysStateDir, confPath
+}
+
+// getBaseStateDir is the state dir of the default profile, the other
+// profiles live in its profiles dir
+func getBaseStateDir(cliParams CliParams) string {
+	if cliParams.StateDir != "" {
+		return cliParams.StateDir
+	}
+	if dir := os.Getenv(STATE_DIR_ENV); dir != "" {
+		return dir
+	}
+	return filepath.Join(getHomePath(), SYS_STATE_DIR)
+}
+
+// isInteractive reports whether stdin is a terminal
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 func getHomePath() string {
 	var homepath string
 	if runtime.GOOS == "windows" {
@@ -42,3 +85,14 @@ func fileExists(filename string) (bool, error) {
 	}
 	return false, err
 }
+
+func dirExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err == nil {
+		return info.IsDir(), nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
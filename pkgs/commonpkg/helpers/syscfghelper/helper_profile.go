@@ -0,0 +1,118 @@
+package syscfghelper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	DEFAULT_PROFILE = "default"
+	PROFILES_DIR    = "profiles"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetProfileName returns the selected profile, from the params then XSYNC_PROFILE
+func GetProfileName(cliParams CliParams) string {
+	name := cliParams.Profile
+	if name == "" {
+		name = os.Getenv(PROFILE_ENV)
+	}
+	if name == "" {
+		return DEFAULT_PROFILE
+	}
+	return name
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, only letters, digits, '-' and '_' are allowed", name)
+	}
+	return nil
+}
+
+// CheckProfile fails when the selected profile is invalid or was never created
+func CheckProfile(cliParams CliParams) error {
+	name := GetProfileName(cliParams)
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if name == DEFAULT_PROFILE {
+		return nil
+	}
+	if ok, _ := dirExists(GetProfileStateDir(cliParams, name)); !ok {
+		return fmt.Errorf("profile %q does not exist, create it with \"xSync profile create %s\"", name, name)
+	}
+	return nil
+}
+
+// GetProfileStateDir holds the config, the additional cookies and the logs of
+// a profile. The default profile keeps the layout of the versions without profiles.
+func GetProfileStateDir(cliParams CliParams, name string) string {
+	base := getBaseStateDir(cliParams)
+	if name == DEFAULT_PROFILE {
+		return base
+	}
+	return filepath.Join(base, PROFILES_DIR, name)
+}
+
+// CheckRootPathFree fails when another profile than name keeps its database
+// and archive in rootPath, as found in the config files of the profiles
+func CheckRootPathFree(cliParams CliParams, name string, rootPath string) error {
+	if rootPath == "" {
+		return nil
+	}
+	names, err := ListProfiles(cliParams)
+	if err != nil {
+		return err
+	}
+	for _, other := range names {
+		if other == name {
+			continue
+		}
+		otherConf, err := config.ParseConfigFromFile(filepath.Join(GetProfileStateDir(cliParams, other), SYS_CONF_FILE))
+		if err == nil && samePath(otherConf.RootPath, rootPath) {
+			return fmt.Errorf("root path %s is already used by profile %q", rootPath, other)
+		}
+	}
+	return nil
+}
+
+func samePath(a string, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// ListProfiles returns the default profile followed by the others by name
+func ListProfiles(cliParams CliParams) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(getBaseStateDir(cliParams), PROFILES_DIR))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateProfileName(entry.Name()) == nil && entry.Name() != DEFAULT_PROFILE {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{DEFAULT_PROFILE}, names...), nil
+}
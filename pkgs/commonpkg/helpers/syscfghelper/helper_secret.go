@@ -0,0 +1,95 @@
+package syscfghelper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/WangWilly/xSync/pkgs/clipkg/config"
+	"golang.org/x/term"
+)
+
+const PASSPHRASE_ENV = "XSYNC_PASSPHRASE"
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GetPassphrase returns XSYNC_PASSPHRASE, or asks for the passphrase of the
+// cookies at the terminal, twice when confirm is set
+func GetPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(PASSPHRASE_ENV); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("no passphrase for the cookies, set %s or run in a terminal", PASSPHRASE_ENV)
+	}
+
+	passphrase, err := readPassword("cookie passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("empty passphrase")
+	}
+	if confirm {
+		again, err := readPassword("repeat the passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// NewCipher creates the cipher of the cookies from GetPassphrase
+func NewCipher(confirm bool) (*config.Cipher, error) {
+	passphrase, err := GetPassphrase(confirm)
+	if err != nil {
+		return nil, err
+	}
+	return config.NewCipher(passphrase)
+}
+
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	data, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// getCipher asks for the passphrase once per run, the first time an
+// encrypted cookie is read
+func (h *helper) getCipher() (*config.Cipher, error) {
+	if h.cipher != nil {
+		return h.cipher, nil
+	}
+	cipher, err := NewCipher(false)
+	if err != nil {
+		return nil, err
+	}
+	h.cipher = cipher
+	return cipher, nil
+}
+
+func (h *helper) decryptCookies(cookies ...*config.Cookie) error {
+	for _, cookie := range cookies {
+		if !cookie.IsEncrypted() {
+			continue
+		}
+		cipher, err := h.getCipher()
+		if err != nil {
+			return err
+		}
+		if err := cipher.DecryptCookie(cookie); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -27,6 +28,31 @@ type UserPreviousName struct {
 	CreatedAt  time.Time `db:"created_at"`
 }
 
+type UserSnapshot struct {
+	Id               int64        `db:"id"`
+	Uid              uint64       `db:"uid"`
+	ScreenName       string       `db:"screen_name"`
+	Name             string       `db:"name"`
+	Description      string       `db:"description"`
+	Location         string       `db:"location"`
+	Url              string       `db:"url"`
+	AvatarUrl        string       `db:"avatar_url"`
+	BannerUrl        string       `db:"banner_url"`
+	FollowersCount   int          `db:"followers_count"`
+	FriendsCount     int          `db:"friends_count"`
+	MediaCount       int          `db:"media_count"`
+	IsVerified       bool         `db:"verified"`
+	AccountCreatedAt sql.NullTime `db:"account_created_at"`
+	CreatedAt        time.Time    `db:"created_at"`
+}
+
+// UserSnapshotChange describes a single profile field that differs between two snapshots
+type UserSnapshotChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
 type UserEntity struct {
 	Id                sql.NullInt32 `db:"id"`
 	Uid               uint64        `db:"user_id"`
@@ -40,6 +66,15 @@ type UserEntity struct {
 	UpdatedAt         time.Time     `db:"updated_at"`
 }
 
+// UserEntityStatus is a user entity with the screen name of its user and the
+// number of tweets and media recorded for it
+type UserEntityStatus struct {
+	UserEntity
+	ScreenName       string `db:"screen_name"`
+	TweetCount       int    `db:"tweet_count"`
+	StoredMediaCount int    `db:"stored_media_count"`
+}
+
 type UserLink struct {
 	Id                   sql.NullInt32 `db:"id"`
 	UserTwitterId        uint64        `db:"user_id"`
@@ -61,6 +96,7 @@ type List struct {
 type ListEntity struct {
 	Id           sql.NullInt32 `db:"id"`
 	LstId        int64         `db:"lst_id"`
+	Kind         string        `db:"kind"`
 	Name         string        `db:"name"`
 	ParentDir    string        `db:"parent_dir"`
 	FolderName   string        `db:"folder_name"`
@@ -69,25 +105,111 @@ type ListEntity struct {
 	UpdatedAt    time.Time     `db:"updated_at"`
 }
 
+const (
+	TWEET_KIND_TWEET     = "tweet"
+	TWEET_KIND_COMMUNITY = "community"
+	TWEET_KIND_SPACE     = "space"
+)
+
+// Tweet is a downloaded tweet; Kind tells the tweets posted in a community
+// and those sharing a recorded Space from the others
 type Tweet struct {
 	Id        int64     `db:"id"`
 	UserId    uint64    `db:"user_id"`
 	TweetId   uint64    `db:"tweet_id"`
 	Content   string    `db:"content"`
 	TweetTime time.Time `db:"tweet_time"`
-	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+	Kind      string    `db:"kind"`
+	// DownloadedAt is set once the media of the tweet were downloaded
+	DownloadedAt sql.NullTime `db:"downloaded_at"`
+	CreatedAt    time.Time    `db:"created_at"`
+	UpdatedAt    time.Time    `db:"updated_at"`
 }
 
+const (
+	MEDIA_KIND_TWEET  = "tweet"
+	MEDIA_KIND_AVATAR = "avatar"
+	MEDIA_KIND_BANNER = "banner"
+	MEDIA_KIND_SPACE  = "space"
+)
+
+// Media is a downloaded file; profile media (avatar/banner) have TweetId 0,
+// the audio of a recorded Space belongs to the tweet sharing it
 type Media struct {
 	Id        int64     `db:"id"`
 	UserId    uint64    `db:"user_id"`
 	TweetId   int64     `db:"tweet_id"`
 	Location  string    `db:"location"`
+	Kind      string    `db:"kind"`
+	Hash      string    `db:"hash"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+const (
+	SUBSCRIPTION_KIND_USER      = "user"
+	SUBSCRIPTION_KIND_USER_NAME = "user-name"
+	SUBSCRIPTION_KIND_LIST      = "list"
+	SUBSCRIPTION_KIND_FOLL      = "foll"
+)
+
+// Subscription is a sync target remembered across runs. Include and Exclude are
+// comma separated user ids or screen names applied to list and following members.
+// IntervalSeconds is how often the daemon re-syncs it, 0 means the daemon default.
+type Subscription struct {
+	Id              int64        `db:"id"`
+	Kind            string       `db:"kind"`
+	Target          string       `db:"target"`
+	Include         string       `db:"include"`
+	Exclude         string       `db:"exclude"`
+	Enabled         bool         `db:"enabled"`
+	IntervalSeconds int64        `db:"interval_seconds"`
+	LastSyncedAt    sql.NullTime `db:"last_synced_at"`
+	CreatedAt       time.Time    `db:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at"`
+}
+
+// Search is a search query archived like a list, into a folder named after
+// it. LatestReleaseTime is the newest tweet found so far, the next search
+// stops there.
+type Search struct {
+	Id                int64        `db:"id"`
+	Query             string       `db:"query"`
+	Name              string       `db:"name"`
+	LatestReleaseTime sql.NullTime `db:"latest_release_time"`
+	CreatedAt         time.Time    `db:"created_at"`
+	UpdatedAt         time.Time    `db:"updated_at"`
+}
+
+// Community is an X Community archived like a search, Id is the id of the
+// community on x.com
+type Community struct {
+	Id                uint64       `db:"id"`
+	Name              string       `db:"name"`
+	LatestReleaseTime sql.NullTime `db:"latest_release_time"`
+	CreatedAt         time.Time    `db:"created_at"`
+	UpdatedAt         time.Time    `db:"updated_at"`
+}
+
+func (s *Subscription) Interval(defaultInterval time.Duration) time.Duration {
+	if s.IntervalSeconds <= 0 {
+		return defaultInterval
+	}
+	return time.Duration(s.IntervalSeconds) * time.Second
+}
+
+// NextSyncAt is the zero time for a subscription that was never synced
+func (s *Subscription) NextSyncAt(defaultInterval time.Duration) time.Time {
+	if !s.LastSyncedAt.Valid {
+		return time.Time{}
+	}
+	return s.LastSyncedAt.Time.Add(s.Interval(defaultInterval))
+}
+
+func (s *Subscription) IsDue(now time.Time, defaultInterval time.Duration) bool {
+	return s.Enabled && !s.NextSyncAt(defaultInterval).After(now)
+}
+
 func (le *ListEntity) Path() string {
 	if le.ParentDir == "" || le.Name == "" {
 		panic("no enough info to get path")
@@ -117,3 +239,44 @@ func (ul *UserLink) Path(db *sqlx.DB) (string, error) {
 
 	return filepath.Join(result.Path(), ul.Name), nil
 }
+
+// Diff lists the profile fields that changed from prev to us; a nil prev
+// means every populated field is reported as new
+func (us *UserSnapshot) Diff(prev *UserSnapshot) []UserSnapshotChange {
+	if prev == nil {
+		prev = &UserSnapshot{}
+	}
+
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"screen_name", prev.ScreenName, us.ScreenName},
+		{"name", prev.Name, us.Name},
+		{"description", prev.Description, us.Description},
+		{"location", prev.Location, us.Location},
+		{"url", prev.Url, us.Url},
+		{"avatar_url", prev.AvatarUrl, us.AvatarUrl},
+		{"banner_url", prev.BannerUrl, us.BannerUrl},
+		{"followers_count", strconv.Itoa(prev.FollowersCount), strconv.Itoa(us.FollowersCount)},
+		{"friends_count", strconv.Itoa(prev.FriendsCount), strconv.Itoa(us.FriendsCount)},
+		{"media_count", strconv.Itoa(prev.MediaCount), strconv.Itoa(us.MediaCount)},
+		{"verified", strconv.FormatBool(prev.IsVerified), strconv.FormatBool(us.IsVerified)},
+		{"account_created_at", formatNullTime(prev.AccountCreatedAt), formatNullTime(us.AccountCreatedAt)},
+	}
+
+	var changes []UserSnapshotChange
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, UserSnapshotChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
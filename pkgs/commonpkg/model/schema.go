@@ -1,6 +1,8 @@
 package model
 
 import (
+	"fmt"
+
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -28,6 +30,27 @@ CREATE TABLE IF NOT EXISTS user_previous_names (
 	FOREIGN KEY(uid) REFERENCES users (id)
 );
 
+CREATE TABLE IF NOT EXISTS user_snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	uid INTEGER NOT NULL,
+	screen_name VARCHAR NOT NULL,
+	name VARCHAR NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	location VARCHAR NOT NULL DEFAULT '',
+	url VARCHAR NOT NULL DEFAULT '',
+	avatar_url VARCHAR NOT NULL DEFAULT '',
+	banner_url VARCHAR NOT NULL DEFAULT '',
+	followers_count INTEGER NOT NULL DEFAULT 0,
+	friends_count INTEGER NOT NULL DEFAULT 0,
+	media_count INTEGER NOT NULL DEFAULT 0,
+	verified BOOLEAN NOT NULL DEFAULT FALSE,
+	account_created_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(uid) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_snapshots_uid ON user_snapshots (uid);
+
 CREATE TABLE IF NOT EXISTS lsts (
 	id INTEGER NOT NULL, 
 	name VARCHAR NOT NULL, 
@@ -103,6 +126,18 @@ CREATE TABLE IF NOT EXISTS medias (
 	FOREIGN KEY(tweet_id) REFERENCES tweets (id)
 );
 
+CREATE TABLE IF NOT EXISTS subscriptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind VARCHAR NOT NULL,
+	target VARCHAR NOT NULL COLLATE NOCASE,
+	include VARCHAR NOT NULL DEFAULT '',
+	exclude VARCHAR NOT NULL DEFAULT '',
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (kind, target)
+);
+
 CREATE INDEX IF NOT EXISTS idx_tweets_user_id ON tweets (user_id);
 CREATE INDEX IF NOT EXISTS idx_tweets_tweet_id ON tweets (tweet_id);
 CREATE INDEX IF NOT EXISTS idx_medias_user_id ON medias (user_id);
@@ -110,6 +145,116 @@ CREATE INDEX IF NOT EXISTS idx_medias_tweet_id ON medias (tweet_id);
 CREATE INDEX IF NOT EXISTS idx_tweets_tweet_time ON tweets (tweet_time);
 `
 
+// Migrations upgrade the tables created by Schema. Migrations[i] moves the
+// database from user_version i to i+1, so entries must only ever be appended.
+var Migrations = []string{
+	// 1: profile media (avatars, banners) live next to tweet media
+	`
+ALTER TABLE medias ADD COLUMN kind VARCHAR NOT NULL DEFAULT 'tweet';
+ALTER TABLE medias ADD COLUMN hash VARCHAR NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_medias_user_id_hash ON medias (user_id, hash);
+`,
+	// 2: per subscription schedules for the daemon
+	`
+ALTER TABLE subscriptions ADD COLUMN interval_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE subscriptions ADD COLUMN last_synced_at DATETIME;
+`,
+	// 3: search queries archived like lists
+	`
+CREATE TABLE IF NOT EXISTS searches (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	query VARCHAR NOT NULL UNIQUE,
+	name VARCHAR NOT NULL,
+	latest_release_time DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	// 4: community tweets and recorded Spaces, communities archived like searches
+	`
+ALTER TABLE tweets ADD COLUMN kind VARCHAR NOT NULL DEFAULT 'tweet';
+CREATE TABLE IF NOT EXISTS communities (
+	id INTEGER PRIMARY KEY,
+	name VARCHAR NOT NULL,
+	latest_release_time DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+`,
+	// 5: tweets are done once downloaded, not once saved; the tweets saved
+	// before were treated as done already
+	`
+ALTER TABLE tweets ADD COLUMN downloaded_at DATETIME;
+UPDATE tweets SET downloaded_at = created_at;
+`,
+	// 6: list folders link the folders of their members
+	`
+ALTER TABLE user_links ADD COLUMN storage_saved BOOLEAN NOT NULL DEFAULT FALSE;
+`,
+	// 7: list folders are told apart by kind too, since pseudo lists such as
+	// searches and communities number their ids on their own. SQLite cannot
+	// change a unique constraint, so the table is rebuilt.
+	`
+CREATE TABLE lst_entities_v7 (
+	id INTEGER NOT NULL,
+	lst_id INTEGER NOT NULL,
+	kind VARCHAR NOT NULL DEFAULT 'twitter_list',
+	name VARCHAR NOT NULL,
+	parent_dir VARCHAR NOT NULL COLLATE NOCASE,
+	folder_name VARCHAR NOT NULL COLLATE NOCASE,
+	storage_saved BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE (kind, lst_id, parent_dir)
+);
+INSERT INTO lst_entities_v7(id, lst_id, name, parent_dir, folder_name, storage_saved, created_at, updated_at)
+	SELECT id, lst_id, name, parent_dir, folder_name, storage_saved, created_at, updated_at FROM lst_entities;
+DROP TABLE lst_entities;
+ALTER TABLE lst_entities_v7 RENAME TO lst_entities;
+`,
+}
+
+// SchemaVersion is the user_version of a fully migrated database
+var SchemaVersion = len(Migrations)
+
 func CreateTables(db *sqlx.DB) {
 	db.MustExec(Schema)
+	if err := Migrate(db); err != nil {
+		panic(err)
+	}
+}
+
+// GetSchemaVersion reads the migration level recorded in the database
+func GetSchemaVersion(db *sqlx.DB) (int, error) {
+	var version int
+	err := db.Get(&version, "PRAGMA user_version")
+	return version, err
+}
+
+// Migrate applies every pending migration, each in its own transaction
+func Migrate(db *sqlx.DB) error {
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for ; version < len(Migrations); version++ {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(Migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", version+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
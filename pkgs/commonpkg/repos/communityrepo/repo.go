@@ -0,0 +1,72 @@
+package communityrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type repo struct{}
+
+func New() *repo {
+	return &repo{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Upsert creates the community or, when it exists already, renames it and
+// reads back where it stopped
+func (r *repo) Upsert(ctx context.Context, db *sqlx.DB, community *model.Community) error {
+	stmt := `INSERT INTO communities(id, name)
+			VALUES(:id, :name)
+			ON CONFLICT(id) DO UPDATE SET name=:name, updated_at=CURRENT_TIMESTAMP
+			RETURNING id, name, latest_release_time, created_at, updated_at`
+	rows, err := db.NamedQueryContext(ctx, stmt, community)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("no rows returned for community %d", community.Id)
+	}
+	if err := rows.StructScan(community); err != nil {
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) GetById(ctx context.Context, db *sqlx.DB, id uint64) (*model.Community, error) {
+	stmt := `SELECT * FROM communities WHERE id=$1`
+	result := &model.Community{}
+	err := db.GetContext(ctx, result, stmt, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *repo) ListAll(ctx context.Context, db *sqlx.DB) ([]*model.Community, error) {
+	stmt := `SELECT * FROM communities ORDER BY id ASC`
+	var communities []*model.Community
+	err := db.SelectContext(ctx, &communities, stmt)
+	return communities, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) UpdateLatestReleaseTime(ctx context.Context, db *sqlx.DB, id uint64, latest time.Time) error {
+	stmt := `UPDATE communities SET latest_release_time=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
+	_, err := db.ExecContext(ctx, stmt, latest, id)
+	return err
+}
@@ -25,9 +25,9 @@ func (r *repo) Create(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	}
 	entity.ParentDir = abs
 
-	stmt := `INSERT INTO lst_entities(lst_id, name, parent_dir, folder_name, storage_saved)
-			VALUES(:lst_id, :name, :parent_dir, :folder_name, :storage_saved)
-			RETURNING id, lst_id, name, parent_dir, folder_name, storage_saved, created_at, updated_at`
+	stmt := `INSERT INTO lst_entities(lst_id, kind, name, parent_dir, folder_name, storage_saved)
+			VALUES(:lst_id, :kind, :name, :parent_dir, :folder_name, :storage_saved)
+			RETURNING id, lst_id, kind, name, parent_dir, folder_name, storage_saved, created_at, updated_at`
 	rows, err := db.NamedQueryContext(ctx, stmt, entity)
 	if err != nil {
 		return err
@@ -35,7 +35,7 @@ func (r *repo) Create(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("no rows returned for entity with lst_id %d and parent_dir %s", entity.LstId, entity.ParentDir)
+		return fmt.Errorf("no rows returned for entity with lst_id %d of %s and parent_dir %s", entity.LstId, entity.Kind, entity.ParentDir)
 	}
 	if err := rows.StructScan(entity); err != nil {
 		return err
@@ -50,10 +50,10 @@ func (r *repo) Upsert(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	}
 	entity.ParentDir = abs
 
-	stmt := `INSERT INTO lst_entities(lst_id, name, parent_dir, folder_name, storage_saved)
-		VALUES(:lst_id, :name, :parent_dir, :folder_name, :storage_saved)
-		ON CONFLICT(lst_id) DO UPDATE SET name=:name, parent_dir=:parent_dir, folder_name=:folder_name, storage_saved=:storage_saved, updated_at=CURRENT_TIMESTAMP
-		RETURNING id, lst_id, name, parent_dir, folder_name, storage_saved, created_at, updated_at`
+	stmt := `INSERT INTO lst_entities(lst_id, kind, name, parent_dir, folder_name, storage_saved)
+		VALUES(:lst_id, :kind, :name, :parent_dir, :folder_name, :storage_saved)
+		ON CONFLICT(kind, lst_id, parent_dir) DO UPDATE SET name=:name, folder_name=:folder_name, storage_saved=:storage_saved, updated_at=CURRENT_TIMESTAMP
+		RETURNING id, lst_id, kind, name, parent_dir, folder_name, storage_saved, created_at, updated_at`
 	rows, err := db.NamedQueryContext(ctx, stmt, entity)
 	if err != nil {
 		return err
@@ -61,7 +61,7 @@ func (r *repo) Upsert(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("no rows returned for upsert of entity with lst_id %d and parent_dir %s", entity.LstId, entity.ParentDir)
+		return fmt.Errorf("no rows returned for upsert of entity with lst_id %d of %s and parent_dir %s", entity.LstId, entity.Kind, entity.ParentDir)
 	}
 	if err := rows.StructScan(entity); err != nil {
 		return err
@@ -85,15 +85,15 @@ func (r *repo) GetById(ctx context.Context, db *sqlx.DB, id int) (*model.ListEnt
 	return result, nil
 }
 
-func (r *repo) Get(ctx context.Context, db *sqlx.DB, lid int64, parentDir string) (*model.ListEntity, error) {
+func (r *repo) Get(ctx context.Context, db *sqlx.DB, kind string, lid int64, parentDir string) (*model.ListEntity, error) {
 	parentDir, err := filepath.Abs(parentDir)
 	if err != nil {
 		return nil, err
 	}
 
-	stmt := `SELECT * FROM lst_entities WHERE lst_id=? AND parent_dir=?`
+	stmt := `SELECT * FROM lst_entities WHERE kind=? AND lst_id=? AND parent_dir=?`
 	result := &model.ListEntity{}
-	err = db.GetContext(ctx, result, stmt, lid, parentDir)
+	err = db.GetContext(ctx, result, stmt, kind, lid, parentDir)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -115,7 +115,7 @@ func (r *repo) Update(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 				storage_saved=:storage_saved,
 				updated_at=CURRENT_TIMESTAMP
 			 WHERE id=?
-			 RETURNING id, lst_id, name, parent_dir, folder_name, storage_saved, created_at, updated_at
+			 RETURNING id, lst_id, kind, name, parent_dir, folder_name, storage_saved, created_at, updated_at
 			`
 	rows, err := db.NamedQueryContext(ctx, stmt, entity)
 	if err != nil {
@@ -124,7 +124,7 @@ func (r *repo) Update(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("no rows returned for update of entity with id %d", entity.Id)
+		return fmt.Errorf("no rows returned for update of entity with id %d", entity.Id.Int32)
 	}
 	if err := rows.StructScan(entity); err != nil {
 		return err
@@ -132,9 +132,9 @@ func (r *repo) Update(ctx context.Context, db *sqlx.DB, entity *model.ListEntity
 	return nil
 }
 
-func (r *repo) UpdateStorageSavedByTwitterId(ctx context.Context, db *sqlx.DB, twitterId uint64, saved bool) error {
-	stmt := `UPDATE lst_entities SET storage_saved=?, updated_at=CURRENT_TIMESTAMP WHERE lst_id=?`
-	_, err := db.ExecContext(ctx, stmt, saved, twitterId)
+func (r *repo) UpdateStorageSavedByTwitterId(ctx context.Context, db *sqlx.DB, kind string, twitterId uint64, saved bool) error {
+	stmt := `UPDATE lst_entities SET storage_saved=?, updated_at=CURRENT_TIMESTAMP WHERE kind=? AND lst_id=?`
+	_, err := db.ExecContext(ctx, stmt, saved, kind, twitterId)
 	return err
 }
 
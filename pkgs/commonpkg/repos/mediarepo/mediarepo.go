@@ -19,9 +19,13 @@ func New() *Repo {
 ////////////////////////////////////////////////////////////////////////////////
 
 func (r *Repo) Create(ctx context.Context, db *sqlx.DB, media *model.Media) error {
-	stmt := `INSERT INTO medias(user_id, tweet_id, location) 
-			 VALUES(:user_id, :tweet_id, :location)
-			 RETURNING id, user_id, tweet_id, location, created_at, updated_at
+	if media.Kind == "" {
+		media.Kind = model.MEDIA_KIND_TWEET
+	}
+
+	stmt := `INSERT INTO medias(user_id, tweet_id, location, kind, hash) 
+			 VALUES(:user_id, :tweet_id, :location, :kind, :hash)
+			 RETURNING id, user_id, tweet_id, location, kind, hash, created_at, updated_at
 			`
 	rows, err := db.NamedQueryContext(ctx, stmt, media)
 	if err != nil {
@@ -57,6 +61,15 @@ func (r *Repo) GetByUserId(ctx context.Context, db *sqlx.DB, userId uint64) ([]*
 	return medias, err
 }
 
+// GetByUserIdAndKind lists the media of the given kind of a user, the newest
+// first
+func (r *Repo) GetByUserIdAndKind(ctx context.Context, db *sqlx.DB, userId uint64, kind string) ([]*model.Media, error) {
+	stmt := `SELECT * FROM medias WHERE user_id=$1 AND kind=$2 ORDER BY created_at DESC`
+	var medias []*model.Media
+	err := db.SelectContext(ctx, &medias, stmt, userId, kind)
+	return medias, err
+}
+
 func (r *Repo) GetByTweetId(ctx context.Context, db *sqlx.DB, tweetId int64) ([]*model.Media, error) {
 	stmt := `SELECT * FROM medias WHERE tweet_id=$1 ORDER BY created_at ASC`
 	var medias []*model.Media
@@ -64,6 +77,17 @@ func (r *Repo) GetByTweetId(ctx context.Context, db *sqlx.DB, tweetId int64) ([]
 	return medias, err
 }
 
+// GetByUserIdAndHash finds a media of the given kind with identical content
+func (r *Repo) GetByUserIdAndHash(ctx context.Context, db *sqlx.DB, userId uint64, kind string, hash string) (*model.Media, error) {
+	stmt := `SELECT * FROM medias WHERE user_id=$1 AND kind=$2 AND hash=$3`
+	result := &model.Media{}
+	err := db.GetContext(ctx, result, stmt, userId, kind, hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return result, err
+}
+
 func (r *Repo) GetByLocation(ctx context.Context, db *sqlx.DB, location string) (*model.Media, error) {
 	stmt := `SELECT * FROM medias WHERE location=$1`
 	result := &model.Media{}
@@ -81,9 +105,10 @@ func (r *Repo) Update(ctx context.Context, db *sqlx.DB, media *model.Media) erro
 	stmt := `UPDATE medias 
 			 SET
 				location=:location,
+				hash=:hash,
 				updated_at=CURRENT_TIMESTAMP
 			 WHERE id=:id
-			 RETURNING id, user_id, tweet_id, location, created_at, updated_at
+			 RETURNING id, user_id, tweet_id, location, kind, hash, created_at, updated_at
 			`
 
 	rows, err := db.
@@ -78,6 +78,8 @@ func setupSchema() {
 		user_id BIGINT NOT NULL,
 		tweet_id BIGINT NOT NULL,
 		location TEXT NOT NULL,
+		kind TEXT NOT NULL DEFAULT 'tweet',
+		hash TEXT NOT NULL DEFAULT '',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
@@ -206,3 +208,79 @@ func TestRepoIntegration_Update(t *testing.T) {
 		assert.Equal(t, originalMedia.CreatedAt.Unix(), updatedMedia.CreatedAt.Unix())
 	})
 }
+
+func TestRepoIntegration_GetByUserIdAndHash(t *testing.T) {
+	ctx := context.Background()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := New()
+
+	// Clear data before tests
+	clearData()
+
+	avatar := &model.Media{
+		UserId:   12345,
+		Location: "/path/to/avatar_20240101.jpg",
+		Kind:     model.MEDIA_KIND_AVATAR,
+		Hash:     "abc123",
+	}
+	require.NoError(t, repo.Create(ctx, db, avatar))
+
+	t.Run("find media with same content", func(t *testing.T) {
+		found, err := repo.GetByUserIdAndHash(ctx, db, 12345, model.MEDIA_KIND_AVATAR, "abc123")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, avatar.Id, found.Id)
+		assert.Equal(t, model.MEDIA_KIND_AVATAR, found.Kind)
+	})
+
+	t.Run("hash is scoped by kind", func(t *testing.T) {
+		found, err := repo.GetByUserIdAndHash(ctx, db, 12345, model.MEDIA_KIND_BANNER, "abc123")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("tweet media is the default kind", func(t *testing.T) {
+		media := &model.Media{UserId: 12345, TweetId: 67890, Location: "/path/to/media.jpg"}
+		require.NoError(t, repo.Create(ctx, db, media))
+		assert.Equal(t, model.MEDIA_KIND_TWEET, media.Kind)
+	})
+}
+
+func TestRepoIntegration_GetByUserIdAndKind(t *testing.T) {
+	ctx := context.Background()
+
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	repo := New()
+
+	// Clear data before tests
+	clearData()
+
+	avatar := &model.Media{
+		UserId:   12345,
+		Location: "/path/to/avatar_20240101.jpg",
+		Kind:     model.MEDIA_KIND_AVATAR,
+		Hash:     "abc123",
+	}
+	require.NoError(t, repo.Create(ctx, db, avatar))
+	require.NoError(t, repo.Create(ctx, db, &model.Media{UserId: 12345, TweetId: 67890, Location: "/path/to/media.jpg"}))
+
+	t.Run("only media of the kind", func(t *testing.T) {
+		found, err := repo.GetByUserIdAndKind(ctx, db, 12345, model.MEDIA_KIND_TWEET)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, int64(67890), found[0].TweetId)
+	})
+
+	t.Run("no media of the kind", func(t *testing.T) {
+		found, err := repo.GetByUserIdAndKind(ctx, db, 12345, model.MEDIA_KIND_BANNER)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
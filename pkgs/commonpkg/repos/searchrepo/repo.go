@@ -0,0 +1,72 @@
+package searchrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type repo struct{}
+
+func New() *repo {
+	return &repo{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Upsert creates the search or, when the query already exists, renames it and
+// reads back where it stopped
+func (r *repo) Upsert(ctx context.Context, db *sqlx.DB, search *model.Search) error {
+	stmt := `INSERT INTO searches(query, name)
+			VALUES(:query, :name)
+			ON CONFLICT(query) DO UPDATE SET name=:name, updated_at=CURRENT_TIMESTAMP
+			RETURNING id, query, name, latest_release_time, created_at, updated_at`
+	rows, err := db.NamedQueryContext(ctx, stmt, search)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("no rows returned for search %s", search.Query)
+	}
+	if err := rows.StructScan(search); err != nil {
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) GetByQuery(ctx context.Context, db *sqlx.DB, query string) (*model.Search, error) {
+	stmt := `SELECT * FROM searches WHERE query=$1`
+	result := &model.Search{}
+	err := db.GetContext(ctx, result, stmt, query)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *repo) ListAll(ctx context.Context, db *sqlx.DB) ([]*model.Search, error) {
+	stmt := `SELECT * FROM searches ORDER BY id ASC`
+	var searches []*model.Search
+	err := db.SelectContext(ctx, &searches, stmt)
+	return searches, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) UpdateLatestReleaseTime(ctx context.Context, db *sqlx.DB, id int64, latest time.Time) error {
+	stmt := `UPDATE searches SET latest_release_time=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
+	_, err := db.ExecContext(ctx, stmt, latest, id)
+	return err
+}
@@ -0,0 +1,126 @@
+package subscriptionrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type repo struct{}
+
+func New() *repo {
+	return &repo{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Upsert creates the subscription or, when the kind and target already exist,
+// replaces its filters, enabled flag and interval
+func (r *repo) Upsert(ctx context.Context, db *sqlx.DB, sub *model.Subscription) error {
+	stmt := `INSERT INTO subscriptions(kind, target, include, exclude, enabled, interval_seconds)
+			VALUES(:kind, :target, :include, :exclude, :enabled, :interval_seconds)
+			ON CONFLICT(kind, target) DO UPDATE SET include=:include, exclude=:exclude, enabled=:enabled, interval_seconds=:interval_seconds, updated_at=CURRENT_TIMESTAMP
+			RETURNING id, kind, target, include, exclude, enabled, interval_seconds, last_synced_at, created_at, updated_at`
+	rows, err := db.NamedQueryContext(ctx, stmt, sub)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("no rows returned for subscription %s %s", sub.Kind, sub.Target)
+	}
+	if err := rows.StructScan(sub); err != nil {
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) GetById(ctx context.Context, db *sqlx.DB, id int64) (*model.Subscription, error) {
+	stmt := `SELECT * FROM subscriptions WHERE id=$1`
+	result := &model.Subscription{}
+	err := db.GetContext(ctx, result, stmt, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *repo) GetByKindAndTarget(ctx context.Context, db *sqlx.DB, kind string, target string) (*model.Subscription, error) {
+	stmt := `SELECT * FROM subscriptions WHERE kind=$1 AND target=$2`
+	result := &model.Subscription{}
+	err := db.GetContext(ctx, result, stmt, kind, target)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *repo) ListAll(ctx context.Context, db *sqlx.DB) ([]*model.Subscription, error) {
+	stmt := `SELECT * FROM subscriptions ORDER BY id ASC`
+	var subs []*model.Subscription
+	err := db.SelectContext(ctx, &subs, stmt)
+	return subs, err
+}
+
+func (r *repo) ListEnabled(ctx context.Context, db *sqlx.DB) ([]*model.Subscription, error) {
+	stmt := `SELECT * FROM subscriptions WHERE enabled=TRUE ORDER BY id ASC`
+	var subs []*model.Subscription
+	err := db.SelectContext(ctx, &subs, stmt)
+	return subs, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SetEnabled fails for a subscription that does not exist
+func (r *repo) SetEnabled(ctx context.Context, db *sqlx.DB, id int64, enabled bool) error {
+	stmt := `UPDATE subscriptions SET enabled=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
+	res, err := db.ExecContext(ctx, stmt, enabled, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, id)
+}
+
+func (r *repo) MarkSynced(ctx context.Context, db *sqlx.DB, id int64, at time.Time) error {
+	stmt := `UPDATE subscriptions SET last_synced_at=$1 WHERE id=$2`
+	_, err := db.ExecContext(ctx, stmt, at, id)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Delete fails for a subscription that does not exist
+func (r *repo) Delete(ctx context.Context, db *sqlx.DB, id int64) error {
+	stmt := `DELETE FROM subscriptions WHERE id=$1`
+	res, err := db.ExecContext(ctx, stmt, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res, id)
+}
+
+func checkAffected(res sql.Result, id int64) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("subscription %d doesn't exist", id)
+	}
+	return nil
+}
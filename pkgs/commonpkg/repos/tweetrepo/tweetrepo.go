@@ -18,9 +18,13 @@ func New() *Repo {
 ////////////////////////////////////////////////////////////////////////////////
 
 func (r *Repo) Create(ctx context.Context, db *sqlx.DB, tweet *model.Tweet) error {
-	stmt := `INSERT INTO tweets(user_id, tweet_id, content, tweet_time) 
-			VALUES(:user_id, :tweet_id, :content, :tweet_time)
-			RETURNING id, user_id, tweet_id, content, tweet_time, created_at, updated_at`
+	if tweet.Kind == "" {
+		tweet.Kind = model.TWEET_KIND_TWEET
+	}
+
+	stmt := `INSERT INTO tweets(user_id, tweet_id, content, tweet_time, kind) 
+			VALUES(:user_id, :tweet_id, :content, :tweet_time, :kind)
+			RETURNING id, user_id, tweet_id, content, tweet_time, kind, downloaded_at, created_at, updated_at`
 	rows, err := db.NamedQueryContext(ctx, stmt, tweet)
 	if err != nil {
 		return err
@@ -55,6 +59,13 @@ func (r *Repo) GetByUserId(ctx context.Context, db *sqlx.DB, userId uint64) ([]*
 	return tweets, err
 }
 
+func (r *Repo) ListAll(ctx context.Context, db *sqlx.DB) ([]*model.Tweet, error) {
+	stmt := `SELECT * FROM tweets ORDER BY user_id, tweet_time DESC`
+	var tweets []*model.Tweet
+	err := db.SelectContext(ctx, &tweets, stmt)
+	return tweets, err
+}
+
 func (r *Repo) GetByTweetId(ctx context.Context, db *sqlx.DB, tweetId uint64) (*model.Tweet, error) {
 	stmt := `SELECT * FROM tweets WHERE tweet_id=$1`
 	result := &model.Tweet{}
@@ -74,6 +85,13 @@ func (r *Repo) Update(ctx context.Context, db *sqlx.DB, tweet *model.Tweet) erro
 	return err
 }
 
+// MarkDownloaded records that the media of the tweet were downloaded
+func (r *Repo) MarkDownloaded(ctx context.Context, db *sqlx.DB, id int64, at time.Time) error {
+	stmt := `UPDATE tweets SET downloaded_at=$1, updated_at=CURRENT_TIMESTAMP WHERE id=$2`
+	_, err := db.ExecContext(ctx, stmt, at, id)
+	return err
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func (r *Repo) Delete(ctx context.Context, db *sqlx.DB, id int64) error {
@@ -85,7 +103,7 @@ func (r *Repo) Delete(ctx context.Context, db *sqlx.DB, id int64) error {
 ////////////////////////////////////////////////////////////////////////////////
 
 func (r *Repo) GetWithMedia(ctx context.Context, db *sqlx.DB, userId uint64) ([]map[string]interface{}, error) {
-	stmt := `SELECT t.*, m.location as media_location 
+	stmt := `SELECT t.id, t.user_id, t.tweet_id, t.content, t.tweet_time, t.created_at, t.updated_at, m.location as media_location 
 			 FROM tweets t 
 			 LEFT JOIN medias m ON t.id = m.tweet_id 
 			 WHERE t.user_id=$1 
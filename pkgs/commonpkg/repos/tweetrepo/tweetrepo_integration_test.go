@@ -65,6 +65,8 @@ func TestMain(m *testing.M) {
 			tweet_id BIGINT NOT NULL,
 			content TEXT,
 			tweet_time TIMESTAMP,
+			kind VARCHAR NOT NULL DEFAULT 'tweet',
+			downloaded_at TIMESTAMP,
 			created_at TIMESTAMP DEFAULT NOW(),
 			updated_at TIMESTAMP DEFAULT NOW()
 		);
@@ -187,6 +189,36 @@ func TestRepoIntegration_Update(t *testing.T) {
 	})
 }
 
+func TestRepoIntegration_MarkDownloaded(t *testing.T) {
+	ctx := context.Background()
+
+	repo := New()
+
+	tweet := &model.Tweet{
+		UserId:    12345,
+		TweetId:   67891,
+		Content:   "Tweet to download",
+		TweetTime: time.Now(),
+	}
+	require.NoError(t, repo.Create(ctx, db, tweet))
+	assert.False(t, tweet.DownloadedAt.Valid)
+
+	t.Run("mark tweet downloaded", func(t *testing.T) {
+		// Arrange
+		at := time.Now().UTC().Truncate(time.Second)
+
+		// Act
+		err := repo.MarkDownloaded(ctx, db, tweet.Id, at)
+
+		// Assert
+		require.NoError(t, err)
+		got, err := repo.GetById(ctx, db, tweet.Id)
+		require.NoError(t, err)
+		assert.True(t, got.DownloadedAt.Valid)
+		assert.WithinDuration(t, at, got.DownloadedAt.Time, time.Second)
+	})
+}
+
 func TestRepoIntegration_GetByUserId(t *testing.T) {
 	ctx := context.Background()
 
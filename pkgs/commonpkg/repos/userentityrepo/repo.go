@@ -122,6 +122,21 @@ func (r *repo) GetByTwitterId(ctx context.Context, db *sqlx.DB, twitterId uint64
 	return result, nil
 }
 
+// ListStatus returns every user entity, the most recently synced first. The
+// archived avatars and banners are not counted as stored media.
+func (r *repo) ListStatus(ctx context.Context, db *sqlx.DB) ([]*model.UserEntityStatus, error) {
+	stmt := `SELECT ue.*,
+				COALESCE(u.screen_name, '') AS screen_name,
+				(SELECT COUNT(*) FROM tweets t WHERE t.user_id=ue.user_id) AS tweet_count,
+				(SELECT COUNT(*) FROM medias m WHERE m.user_id=ue.user_id AND m.kind NOT IN ('avatar', 'banner')) AS stored_media_count
+			FROM user_entities ue
+			LEFT JOIN users u ON u.id=ue.user_id
+			ORDER BY ue.updated_at DESC, ue.id DESC`
+	var result []*model.UserEntityStatus
+	err := db.SelectContext(ctx, &result, stmt)
+	return result, err
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func (r *repo) Update(ctx context.Context, db *sqlx.DB, entity *model.UserEntity) error {
@@ -144,7 +159,7 @@ func (r *repo) Update(ctx context.Context, db *sqlx.DB, entity *model.UserEntity
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("no rows returned for update of user entity with id %d", entity.Id)
+		return fmt.Errorf("no rows returned for update of user entity with id %d", entity.Id.Int32)
 	}
 	if err := rows.StructScan(entity); err != nil {
 		return err
@@ -0,0 +1,69 @@
+package usersnapshotrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
+	"github.com/jmoiron/sqlx"
+)
+
+type repo struct{}
+
+func New() *repo {
+	return &repo{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) Create(ctx context.Context, db *sqlx.DB, snapshot *model.UserSnapshot) error {
+	stmt := `INSERT INTO user_snapshots(uid, screen_name, name, description, location, url, avatar_url, banner_url, followers_count, friends_count, media_count, verified, account_created_at)
+			VALUES(:uid, :screen_name, :name, :description, :location, :url, :avatar_url, :banner_url, :followers_count, :friends_count, :media_count, :verified, :account_created_at)
+			RETURNING id, uid, screen_name, name, description, location, url, avatar_url, banner_url, followers_count, friends_count, media_count, verified, account_created_at, created_at`
+	rows, err := db.NamedQueryContext(ctx, stmt, snapshot)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return fmt.Errorf("no rows returned for user snapshot with uid %d", snapshot.Uid)
+	}
+	if err := rows.StructScan(snapshot); err != nil {
+		return err
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) GetLatestByUid(ctx context.Context, db *sqlx.DB, uid uint64) (*model.UserSnapshot, error) {
+	stmt := `SELECT * FROM user_snapshots WHERE uid=$1 ORDER BY id DESC LIMIT 1`
+	result := &model.UserSnapshot{}
+	err := db.GetContext(ctx, result, stmt, uid)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListByUid returns every snapshot of a user, oldest first
+func (r *repo) ListByUid(ctx context.Context, db *sqlx.DB, uid uint64) ([]*model.UserSnapshot, error) {
+	stmt := `SELECT * FROM user_snapshots WHERE uid=$1 ORDER BY id ASC`
+	var snapshots []*model.UserSnapshot
+	err := db.SelectContext(ctx, &snapshots, stmt, uid)
+	return snapshots, err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (r *repo) Delete(ctx context.Context, db *sqlx.DB, id int64) error {
+	stmt := `DELETE FROM user_snapshots WHERE id=$1`
+	_, err := db.ExecContext(ctx, stmt, id)
+	return err
+}
@@ -67,6 +67,37 @@ func (hp *Heap[T]) Peek() T {
 	return hp.data[0]
 }
 
+// TryPop removes and returns the top of the heap in one step, so concurrent
+// callers never pop an element another one peeked
+func (hp *Heap[T]) TryPop() (T, bool) {
+	hp.mtx.Lock()
+	defer hp.mtx.Unlock()
+
+	var top T
+	n := len(hp.data)
+	if n == 0 {
+		return top, false
+	}
+
+	top = hp.data[0]
+	hp.swap(0, n-1)
+	hp.data = hp.data[:n-1]
+	hp.siftDown(0)
+	return top, true
+}
+
+// TryPeek returns the top of the heap without panicking when it is empty
+func (hp *Heap[T]) TryPeek() (T, bool) {
+	hp.mtx.Lock()
+	defer hp.mtx.Unlock()
+
+	var top T
+	if len(hp.data) == 0 {
+		return top, false
+	}
+	return hp.data[0], true
+}
+
 func (hp *Heap[T]) Size() int {
 	hp.mtx.Lock()
 	defer hp.mtx.Unlock()
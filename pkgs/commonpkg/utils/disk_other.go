@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+// +build !unix,!windows
+
+package utils
+
+import "errors"
+
+// GetFreeSpace is not supported on this platform
+func GetFreeSpace(path string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
@@ -0,0 +1,16 @@
+//go:build unix
+// +build unix
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// GetFreeSpace returns the bytes available to the current user on the file
+// system of path
+func GetFreeSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
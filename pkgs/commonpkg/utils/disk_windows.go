@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package utils
+
+import "golang.org/x/sys/windows"
+
+// GetFreeSpace returns the bytes available to the current user on the volume
+// of path
+func GetFreeSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &free, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type delayedItem[T any] struct {
+	val   T
+	until time.Time
+	seq   uint64
+}
+
+// Scheduler hands out the items of a priority heap, holding back the deferred
+// ones until their time comes
+type Scheduler[T any] struct {
+	ready   *Heap[T]
+	delayed *Heap[*delayedItem[T]]
+	seq     uint64
+	wake    chan struct{}
+	mtx     sync.Mutex
+}
+
+// NewScheduler schedules the items of ready, which keeps its own order
+func NewScheduler[T any](ready *Heap[T]) *Scheduler[T] {
+	return &Scheduler[T]{
+		ready: ready,
+		delayed: NewHeap(func(l, r *delayedItem[T]) bool {
+			if l.until.Equal(r.until) {
+				return l.seq < r.seq
+			}
+			return l.until.Before(r.until)
+		}),
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Push makes the item eligible right away
+func (s *Scheduler[T]) Push(val T) {
+	s.ready.Push(val)
+	s.notify()
+}
+
+// Defer holds the item back until the given time, items deferred to the same
+// time come out in the order they were deferred
+func (s *Scheduler[T]) Defer(val T, until time.Time) {
+	s.mtx.Lock()
+	s.seq++
+	s.delayed.Push(&delayedItem[T]{val: val, until: until, seq: s.seq})
+	s.mtx.Unlock()
+	s.notify()
+}
+
+// Next returns the top eligible item, sleeping until the earliest deferred one
+// is due when none is. It returns false once nothing is left or ctx is done.
+func (s *Scheduler[T]) Next(ctx context.Context) (T, bool) {
+	var zero T
+	for ctx.Err() == nil {
+		s.mtx.Lock()
+		now := time.Now()
+		for {
+			top, ok := s.delayed.TryPeek()
+			if !ok || top.until.After(now) {
+				break
+			}
+			s.delayed.Pop()
+			s.ready.Push(top.val)
+		}
+		if val, ok := s.ready.TryPop(); ok {
+			s.mtx.Unlock()
+			return val, true
+		}
+		top, ok := s.delayed.TryPeek()
+		s.mtx.Unlock()
+		if !ok {
+			return zero, false
+		}
+
+		timer := time.NewTimer(top.until.Sub(now))
+		select {
+		case <-ctx.Done():
+		case <-s.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+	return zero, false
+}
+
+// NextEligibleAt is when the earliest deferred item is due, false when none is
+// deferred
+func (s *Scheduler[T]) NextEligibleAt() (time.Time, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	top, ok := s.delayed.TryPeek()
+	if !ok {
+		return time.Time{}, false
+	}
+	return top.until, true
+}
+
+// Len counts the eligible and the deferred items
+func (s *Scheduler[T]) Len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.ready.Size() + s.delayed.Size()
+}
+
+func (s *Scheduler[T]) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
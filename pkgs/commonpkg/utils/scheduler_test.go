@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newIntScheduler(nums ...int) *Scheduler[int] {
+	return NewScheduler(NewByHeapify(nums, func(a, b int) bool { return a < b }))
+}
+
+func TestSchedulerReadyOrder(t *testing.T) {
+	s := newIntScheduler(3, 1, 2)
+	ctx := context.Background()
+
+	for _, want := range []int{1, 2, 3} {
+		got, ok := s.Next(ctx)
+		if !ok || got != want {
+			t.Errorf("Next() = %d, %v, want %d", got, ok, want)
+		}
+	}
+	if _, ok := s.Next(ctx); ok {
+		t.Error("Next() on an empty scheduler returned an item")
+	}
+}
+
+func TestSchedulerDefer(t *testing.T) {
+	s := newIntScheduler(5)
+	start := time.Now()
+	s.Defer(1, start.Add(60*time.Millisecond))
+	s.Defer(2, start.Add(30*time.Millisecond))
+	ctx := context.Background()
+
+	// the eligible item comes first even though the deferred ones rank higher
+	wants := []struct {
+		val   int
+		after time.Duration
+	}{
+		{5, 0},
+		{2, 30 * time.Millisecond},
+		{1, 60 * time.Millisecond},
+	}
+	for _, want := range wants {
+		got, ok := s.Next(ctx)
+		if !ok || got != want.val {
+			t.Fatalf("Next() = %d, %v, want %d", got, ok, want.val)
+		}
+		if elapsed := time.Since(start); elapsed < want.after {
+			t.Errorf("%d came out after %s, want at least %s", got, elapsed, want.after)
+		}
+	}
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", s.Len())
+	}
+}
+
+// TestSchedulerRateWindows runs users through two clients with a quota of
+// two requests per window, deferring a user to the earliest reset of the
+// clients when both are exhausted, the way the producer does
+func TestSchedulerRateWindows(t *testing.T) {
+	const window = 40 * time.Millisecond
+	type client struct {
+		remaining int
+		reset     time.Time
+	}
+	start := time.Now()
+	clients := []*client{
+		{remaining: 2, reset: start.Add(window)},
+		{remaining: 2, reset: start.Add(2 * window)},
+	}
+	selectClient := func(now time.Time) (*client, time.Time) {
+		var earliest time.Time
+		for _, c := range clients {
+			if !now.Before(c.reset) {
+				c.remaining = 2
+				c.reset = now.Add(window)
+			}
+			if c.remaining > 0 {
+				return c, time.Time{}
+			}
+			if earliest.IsZero() || c.reset.Before(earliest) {
+				earliest = c.reset
+			}
+		}
+		return nil, earliest
+	}
+
+	s := newIntScheduler(0, 1, 2, 3, 4, 5, 6, 7)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var done []int
+	deferrals := 0
+	for {
+		user, ok := s.Next(ctx)
+		if !ok {
+			break
+		}
+		now := time.Now()
+		c, at := selectClient(now)
+		if c == nil {
+			if at.Before(now) {
+				t.Fatalf("user %d deferred to %s, before now", user, at)
+			}
+			deferrals++
+			s.Defer(user, at)
+			continue
+		}
+		c.remaining--
+		done = append(done, user)
+	}
+
+	if ctx.Err() != nil {
+		t.Fatal("the scheduler did not drain before the timeout")
+	}
+	if len(done) != 8 {
+		t.Fatalf("got %d users done, want 8: %v", len(done), done)
+	}
+	// four users fit in the first windows, the rest waited for a reset
+	if deferrals < 4 {
+		t.Errorf("got %d deferrals, want at least 4", deferrals)
+	}
+	if elapsed := time.Since(start); elapsed < window {
+		t.Errorf("drained after %s, before the first window reset", elapsed)
+	}
+}
+
+func TestSchedulerPushWakesNext(t *testing.T) {
+	s := newIntScheduler()
+	s.Defer(1, time.Now().Add(time.Hour))
+
+	got := make(chan int, 1)
+	go func() {
+		val, _ := s.Next(context.Background())
+		got <- val
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	s.Push(2)
+	select {
+	case val := <-got:
+		if val != 2 {
+			t.Errorf("Next() = %d, want 2", val)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Push did not wake up Next")
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	s := newIntScheduler()
+	s.Defer(1, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, ok := s.Next(ctx); ok {
+		t.Error("Next() returned an item after the context was done")
+	}
+	if at, ok := s.NextEligibleAt(); !ok || time.Until(at) < 59*time.Minute {
+		t.Errorf("NextEligibleAt() = %s, %v, want in an hour", at, ok)
+	}
+}
+
+func TestSchedulerConcurrentPush(t *testing.T) {
+	s := newIntScheduler()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if j%2 == 0 {
+					s.Push(i*100 + j)
+				} else {
+					s.Defer(i*100+j, time.Now())
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool)
+	for {
+		val, ok := s.Next(context.Background())
+		if !ok {
+			break
+		}
+		if seen[val] {
+			t.Fatalf("%d came out twice", val)
+		}
+		seen[val] = true
+	}
+	if len(seen) != 800 {
+		t.Errorf("got %d items, want 800", len(seen))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"syscall"
@@ -21,6 +22,16 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// RESCHEDULE_MARGIN is waited past a reset time, Twitter may be a bit late
+	RESCHEDULE_MARGIN = 5 * time.Second
+	// PRODUCER_RETRY_DELAY is how long a user waits after a failed request
+	PRODUCER_RETRY_DELAY = 30 * time.Second
+	// PRODUCER_MAX_ATTEMPTS is how many times a user is requested before it is
+	// left to the next run
+	PRODUCER_MAX_ATTEMPTS = 3
+)
+
 // dbWorker extends the regular worker with database integration for tweets and media
 type dbWorker struct {
 	db *sqlx.DB
@@ -53,7 +64,9 @@ func NewDBWorker(
 
 ////////////////////////////////////////////////////////////////////////////////
 
-// ProduceFromHeapToTweetChanWithDB produces tweets from heap and saves them to database
+// ProduceFromHeapToTweetChanWithDB produces tweets from heap and saves them to database.
+// Users whose clients are all rate limited are deferred until the first reset
+// instead of being retried right away.
 func (w *dbWorker) ProduceFromHeapToTweetChanWithDB(
 	ctx context.Context,
 	cancel context.CancelCauseFunc,
@@ -62,22 +75,27 @@ func (w *dbWorker) ProduceFromHeapToTweetChanWithDB(
 ) ([]*dldto.NewEntity, error) {
 	logger := log.WithField("function", "ProduceFromHeapWithDB")
 
-	heap := w.heapHelper.GetHeap()
+	scheduler := utils.NewScheduler(w.heapHelper.GetHeap())
 	logger.
 		WithField("worker", "producer").
-		Infof("initial heap size: %d", heap.Size())
+		Infof("initial heap size: %d", scheduler.Len())
 
+	failures := make(map[*smartpathdto.UserSmartPath]int)
 	var unsentTweets []*dldto.NewEntity
-	for !heap.Empty() && ctx.Err() == nil {
-		entity := heap.Peek()
-		heap.Pop()
+	for {
+		entity, ok := scheduler.Next(ctx)
+		if !ok {
+			break
+		}
 
 		logger.
 			WithField("user", entity.Name()).
 			Infoln("processing user from heap with database integration")
-		currUnsentTweets := w.fetchTweetOrFallbackToHeapWithDB(
+		currUnsentTweets := w.fetchTweetOrDeferWithDB(
 			ctx,
 			cancel,
+			scheduler,
+			failures,
 			entity,
 			output,
 			incrementProduced,
@@ -100,79 +118,127 @@ func (w *dbWorker) ProduceFromHeapToTweetChanWithDB(
 	return unsentTweets, nil
 }
 
-func (w *dbWorker) fetchTweetOrFallbackToHeapWithDB(
+// selectClient returns a client that can list the medias of user now, or else
+// when the first one can. Protected users are only visible to the master
+// client, which follows them. It returns false when no client is left.
+func (w *dbWorker) selectClient(user *twitterclient.User) (*twitterclient.Client, time.Time, bool) {
+	path := twitterclient.GraphQLPath(twitterclient.GRAPHQL_USER_MEDIA)
+	if user.IsProtected {
+		master := w.twitterClientManager.GetMasterClient()
+		if master == nil || master.GetError() != nil {
+			return nil, time.Time{}, false
+		}
+		if at := w.twitterClientManager.AvailableAt(master, path); !at.IsZero() {
+			return nil, at, true
+		}
+		return master, time.Time{}, true
+	}
+
+	if client := w.twitterClientManager.TrySelectClient(path); client != nil {
+		return client, time.Time{}, true
+	}
+	at, ok := w.twitterClientManager.NextAvailableAt(path)
+	return nil, at, ok
+}
+
+func (w *dbWorker) fetchTweetOrDeferWithDB(
 	ctx context.Context,
 	cancel context.CancelCauseFunc,
+	scheduler *utils.Scheduler[*smartpathdto.UserSmartPath],
+	failures map[*smartpathdto.UserSmartPath]int,
 	entity *smartpathdto.UserSmartPath,
 	tweetDlMetaOutput chan<- *dldto.NewEntity,
 	incrementProduced func(),
 ) []*dldto.NewEntity {
-	logger := log.WithField("function", "fetchTweetOrFailbackToHeapWithDB")
+	logger := log.WithField("function", "fetchTweetOrDeferWithDB")
 	logger.WithField("user", entity.Name()).Infoln("fetching user tweets with database integration")
 
 	defer utils.PanicHandler(cancel)
 
 	user := w.heapHelper.GetUserByTwitterId(entity.TwitterId())
-	heap := w.heapHelper.GetHeap()
-	safePushToHeap := func(reason string) {
-		logger.WithField("user", entity.Name()).Warnf("%s, pushing back to heap", reason)
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					logger.WithField("user", entity.Name()).Errorf("panic while pushing to heap: %v", r)
-				}
-			}()
-			heap.Push(entity)
-		}()
+	deferUntil := func(reason string, at time.Time) {
+		if now := time.Now(); at.Before(now) {
+			at = now
+		}
+		at = at.Add(RESCHEDULE_MARGIN)
+		logger.WithField("user", entity.Name()).Warnf("%s, deferring until %s", reason, at.Format(time.TimeOnly))
+		scheduler.Defer(entity, at)
+	}
+	retryLater := func(reason string) {
+		failures[entity]++
+		if failures[entity] >= PRODUCER_MAX_ATTEMPTS {
+			logger.WithField("user", entity.Name()).Errorf("%s, giving up after %d attempts", reason, failures[entity])
+			return
+		}
+		deferUntil(reason, time.Now().Add(PRODUCER_RETRY_DELAY))
 	}
 
 	if ctx.Err() != nil {
-		safePushToHeap("context cancelled")
 		return nil
 	}
 
 	logger.
 		WithField("user", entity.Name()).
 		Infof("latest release time: %s", entity.LatestReleaseTime())
-	client := w.twitterClientManager.GetMasterClient()
-	if client == nil {
-		safePushToHeap("no client available")
+	client, availableAt, ok := w.selectClient(user)
+	if !ok {
+		if _, ok := w.twitterClientManager.NextAvailableAt(twitterclient.GraphQLPath(twitterclient.GRAPHQL_USER_MEDIA)); ok {
+			logger.WithField("user", entity.Name()).Errorln("skipping protected user, the master client is unavailable")
+			return nil
+		}
 		cancel(fmt.Errorf("no client available"))
 		return nil
 	}
+	if client == nil {
+		deferUntil("all clients would block", availableAt)
+		return nil
+	}
 
 	tweets, err := client.ListTweetsByUserAndTimeRange(
 		ctx,
 		user,
 		utils.TimeRange{Begin: entity.LatestReleaseTime()},
 	)
-	if err == twitterclient.ErrWouldBlock {
-		safePushToHeap("client would block")
+	if errors.Is(err, twitterclient.ErrWouldBlock) {
+		deferUntil("client would block", w.twitterClientManager.AvailableAt(client, twitterclient.GraphQLPath(twitterclient.GRAPHQL_USER_MEDIA)))
+		return nil
+	}
+	if utils.IsStatusCode(err, http.StatusTooManyRequests) {
+		// the client may be quarantined by now, the user waits for the next one
+		_, availableAt, _ := w.selectClient(user)
+		deferUntil("too many requests", availableAt)
 		return nil
 	}
 	if v, ok := err.(*twitterclient.TwitterApiError); ok {
 		logger.WithField("user", entity.Name()).Warnf("twitter api error: %s", v.Error())
 		switch v.Code {
 		case twitterclient.ErrExceedPostLimit:
-			w.twitterClientManager.SetClientError(client, fmt.Errorf("reached the limit for seeing posts today"))
-			safePushToHeap("exceed post limit")
+			// another client takes the user over while this one cools off
+			w.twitterClientManager.QuarantineClient(
+				client,
+				time.Now().Add(twitterclient.POST_LIMIT_COOL_OFF),
+				"reached the limit for seeing posts today",
+			)
+			scheduler.Push(entity)
 			return nil
 		case twitterclient.ErrAccountLocked:
 			w.twitterClientManager.SetClientError(client, fmt.Errorf("account is locked"))
-			safePushToHeap("account locked")
+			scheduler.Push(entity)
 			return nil
 		}
 	}
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
 		logger.
 			WithField("user", entity.Name()).
 			Errorln("failed to get user medias:", err)
-		safePushToHeap("failed to get user medias")
+		retryLater("failed to get user medias")
 		return nil
 	}
 
 	if ctx.Err() != nil {
-		safePushToHeap("context cancelled while getting user medias")
 		return nil
 	}
 
@@ -197,20 +263,21 @@ func (w *dbWorker) fetchTweetOrFallbackToHeapWithDB(
 		}).
 		Infoln("found tweets, saving to database and preparing to push to tweet channel")
 
-	// Save tweets to database before processing
-	w.saveTweetsToDatabase(ctx, tweets, entity.TwitterId(), logger)
+	// Save tweets to database before processing, the tweets saved before, such
+	// as by "xSync get", are not downloaded again
+	newTweets := w.saveTweetsToDatabase(ctx, tweets, entity.TwitterId(), logger)
 
 	currIdx := 0
 tweetLoop:
-	for currIdx = range tweets {
-		tweetDlMeta := dldto.NewEntity{Tweet: tweets[currIdx], Entity: entity}
+	for currIdx = range newTweets {
+		tweetDlMeta := dldto.NewEntity{Tweet: newTweets[currIdx], Entity: entity}
 
 		timeoutTimer := time.NewTimer(w.pushTimeout)
 		select {
 		case tweetDlMetaOutput <- &tweetDlMeta:
 			timeoutTimer.Stop()
 			incrementProduced()
-			logger.WithField("user", entity.Name()).Debugf("pushed tweet %d to tweet channel", tweets[currIdx].Id)
+			logger.WithField("user", entity.Name()).Debugf("pushed tweet %d to tweet channel", newTweets[currIdx].Id)
 		case <-ctx.Done():
 			timeoutTimer.Stop()
 			logger.WithField("user", entity.Name()).Warnln("context cancelled while pushing tweet to channel")
@@ -222,8 +289,8 @@ tweetLoop:
 	}
 
 	var tweetsNotSent []*dldto.NewEntity
-	for i := currIdx; i < len(tweets); i++ {
-		tweetsNotSent = append(tweetsNotSent, &dldto.NewEntity{Tweet: tweets[i], Entity: entity})
+	for i := currIdx; i < len(newTweets); i++ {
+		tweetsNotSent = append(tweetsNotSent, &dldto.NewEntity{Tweet: newTweets[i], Entity: entity})
 	}
 	logger.
 		WithField("user", entity.Name()).
@@ -242,19 +309,59 @@ tweetLoop:
 	return tweetsNotSent
 }
 
-// saveTweetsToDatabase saves tweets to the database
+// SaveNewTweetsWithDB saves the tweets of the user entity to the database,
+// to be downloaded into its folder, and returns those not saved before
+func (w *dbWorker) SaveNewTweetsWithDB(
+	ctx context.Context,
+	entity *smartpathdto.UserSmartPath,
+	tweets []*twitterclient.Tweet,
+) []*dldto.NewEntity {
+	logger := log.WithField("function", "SaveNewTweetsWithDB").WithField("user", entity.Name())
+
+	var res []*dldto.NewEntity
+	for _, tweet := range w.saveTweetsToDatabase(ctx, tweets, entity.TwitterId(), logger) {
+		res = append(res, &dldto.NewEntity{Tweet: tweet, Entity: entity})
+	}
+	return res
+}
+
+// saveTweetsToDatabase saves tweets to the database and returns those not
+// downloaded before. The tweets saved by a run stopped before downloading
+// them are returned again.
 func (w *dbWorker) saveTweetsToDatabase(
 	ctx context.Context,
 	tweets []*twitterclient.Tweet,
 	userId uint64,
 	logger *log.Entry,
-) {
+) []*twitterclient.Tweet {
+	newTweets := make([]*twitterclient.Tweet, 0, len(tweets))
 	for _, tweet := range tweets {
+		saved, err := w.tweetRepo.GetByTweetId(ctx, w.db, tweet.Id)
+		if err != nil {
+			logger.
+				WithFields(log.Fields{
+					"tweet_id": tweet.Id,
+					"error":    err,
+				}).
+				Error("failed to get tweet from database")
+		}
+		if saved != nil && saved.DownloadedAt.Valid {
+			logger.
+				WithField("tweet_id", tweet.Id).
+				Debug("tweet was downloaded before, skipping")
+			continue
+		}
+		newTweets = append(newTweets, tweet)
+		if saved != nil {
+			continue
+		}
+
 		dbTweet := &model.Tweet{
 			UserId:    userId,
 			TweetId:   tweet.Id,
 			Content:   tweet.Text,
 			TweetTime: tweet.CreatedAt,
+			Kind:      tweetKind(tweet),
 		}
 
 		if err := w.tweetRepo.Create(ctx, w.db, dbTweet); err != nil {
@@ -274,6 +381,7 @@ func (w *dbWorker) saveTweetsToDatabase(
 			}).
 			Debug("saved tweet to database")
 	}
+	return newTweets
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -389,6 +497,15 @@ func (w *dbWorker) downloadTweetMediaWithDB(
 	}
 
 	dbTweetId := dbTweet.Id
+	// a failed Space fails the tweet, so it is kept for Retry
+	if tweet.SpaceId != "" {
+		if err := w.downloadSpaceWithDB(ctx, tweetDlMeta, dbTweetId, logger); err != nil {
+			return err
+		}
+	}
+
+	// a failed media fails the tweet, so it is kept for Retry
+	failed := 0
 	var urls []string
 	var mediaRecords []*model.Media
 	for i, url := range tweet.Urls {
@@ -411,7 +528,21 @@ func (w *dbWorker) downloadTweetMediaWithDB(
 
 		// Construct the full path where the media should be saved
 		mediaPath := filepath.Join(tweetDlMeta.GetPath(), fileName)
-		dbMedia := &model.Media{
+		// a run stopped before downloading the tweet saved its media already
+		dbMedia, err := w.mediaRepo.GetByLocation(ctx, w.db, mediaPath)
+		if err != nil {
+			logger.WithFields(log.Fields{
+				"tweet_id":   tweet.Id,
+				"media_path": mediaPath,
+				"error":      err,
+			}).Error("failed to get media from database")
+		}
+		if dbMedia != nil {
+			urls = append(urls, url)
+			mediaRecords = append(mediaRecords, dbMedia)
+			continue
+		}
+		dbMedia = &model.Media{
 			UserId:   tweet.Creator.TwitterId,
 			TweetId:  dbTweetId,
 			Location: mediaPath,
@@ -425,6 +556,7 @@ func (w *dbWorker) downloadTweetMediaWithDB(
 				"media_path":  mediaPath,
 				"error":       err,
 			}).Error("failed to save media to database")
+			failed++
 			continue
 		}
 
@@ -452,6 +584,7 @@ func (w *dbWorker) downloadTweetMediaWithDB(
 					"error": err,
 				}).
 				Error("failed to create directory for media")
+			failed++
 			continue
 		}
 
@@ -467,8 +600,86 @@ func (w *dbWorker) downloadTweetMediaWithDB(
 					"error":    err,
 				}).
 				Error("failed to download media file")
+			failed++
 		}
 	}
+	if failed > 0 {
+		return fmt.Errorf("failed to download %d of %d media of tweet %d", failed, len(tweet.Urls), tweet.Id)
+	}
 
+	if err := w.tweetRepo.MarkDownloaded(ctx, w.db, dbTweetId, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark tweet %d as downloaded: %w", tweet.Id, err)
+	}
 	return nil
 }
+
+// downloadSpaceWithDB downloads the recording of the Space shared by the tweet
+// into the folder of its host. The Spaces of other hosts, and those without a
+// recording, are left out.
+func (w *dbWorker) downloadSpaceWithDB(
+	ctx context.Context,
+	tweetDlMeta *dldto.NewEntity,
+	dbTweetId int64,
+	logger *log.Entry,
+) error {
+	tweet := tweetDlMeta.GetTweet()
+	logger = logger.WithFields(log.Fields{
+		"tweet_id": tweet.Id,
+		"space_id": tweet.SpaceId,
+	})
+
+	client := w.twitterClientManager.GetMasterClient()
+	space, err := client.GetAudioSpaceById(ctx, tweet.SpaceId)
+	if err != nil {
+		return fmt.Errorf("failed to get space %s: %w", tweet.SpaceId, err)
+	}
+	if space.Creator.TwitterId != tweet.Creator.TwitterId {
+		logger.Infof("space of %s is left to its host", space.Creator.Title())
+		return nil
+	}
+	if !space.Replayable {
+		logger.Infof("space is %s without recording, skipping", space.State)
+		return nil
+	}
+
+	mediaPath := filepath.Join(tweetDlMeta.GetPath(), space.Id+".aac")
+	existing, err := w.mediaRepo.GetByLocation(ctx, w.db, mediaPath)
+	if err != nil {
+		return fmt.Errorf("failed to get space %s from database: %w", tweet.SpaceId, err)
+	}
+	if existing != nil {
+		logger.Debugln("space was downloaded before, skipping")
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mediaPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for space %s: %w", tweet.SpaceId, err)
+	}
+	if err := client.DownloadSpace(ctx, space, mediaPath); err != nil {
+		return fmt.Errorf("failed to download space %s: %w", tweet.SpaceId, err)
+	}
+
+	// the row is only saved once the recording is on disk
+	dbMedia := &model.Media{
+		UserId:   tweet.Creator.TwitterId,
+		TweetId:  dbTweetId,
+		Location: mediaPath,
+		Kind:     model.MEDIA_KIND_SPACE,
+	}
+	if err := w.mediaRepo.Create(ctx, w.db, dbMedia); err != nil {
+		return fmt.Errorf("failed to save space %s to database: %w", tweet.SpaceId, err)
+	}
+	return nil
+}
+
+// tweetKind tells the tweets sharing a Space, then those of a community, from
+// the others
+func tweetKind(tweet *twitterclient.Tweet) string {
+	switch {
+	case tweet.SpaceId != "":
+		return model.TWEET_KIND_SPACE
+	case tweet.CommunityId != 0:
+		return model.TWEET_KIND_COMMUNITY
+	}
+	return model.TWEET_KIND_TWEET
+}
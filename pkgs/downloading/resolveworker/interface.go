@@ -30,8 +30,10 @@ type UserEntityRepo interface {
 type TweetRepo interface {
 	Create(ctx context.Context, db *sqlx.DB, tweet *model.Tweet) error
 	GetByTweetId(ctx context.Context, db *sqlx.DB, tweetId uint64) (*model.Tweet, error)
+	MarkDownloaded(ctx context.Context, db *sqlx.DB, id int64, at time.Time) error
 }
 
 type MediaRepo interface {
 	Create(ctx context.Context, db *sqlx.DB, media *model.Media) error
+	GetByLocation(ctx context.Context, db *sqlx.DB, location string) (*model.Media, error)
 }
@@ -49,7 +49,8 @@ func (s *Server) getDashboardData() (*serverdto.DashboardData, error) {
 	var dbTotalTweets int
 	var dbTotalMedias int
 	s.db.Get(&dbTotalTweets, "SELECT COUNT(*) FROM tweets")
-	s.db.Get(&dbTotalMedias, "SELECT COUNT(*) FROM medias")
+	// the archived avatars and banners are not media of tweets
+	s.db.Get(&dbTotalMedias, "SELECT COUNT(*) FROM medias WHERE kind NOT IN ('avatar', 'banner')")
 
 	// Use database counts if available, otherwise fallback to dumper
 	if dbTotalTweets > 0 {
@@ -74,7 +75,7 @@ func (s *Server) getDashboardData() (*serverdto.DashboardData, error) {
 		var userTweets int
 		var userMedias int
 		s.db.Get(&userTweets, "SELECT COUNT(*) FROM tweets WHERE user_id = ?", user.Id)
-		s.db.Get(&userMedias, "SELECT COUNT(*) FROM medias WHERE user_id = ?", user.Id)
+		s.db.Get(&userMedias, "SELECT COUNT(*) FROM medias WHERE user_id = ? AND kind NOT IN ('avatar', 'banner')", user.Id)
 
 		stats.TotalMedias = userMedias
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
 	"github.com/WangWilly/xSync/pkgs/serverpkg/serverdto"
 )
 
@@ -24,8 +25,8 @@ func (s *Server) handleMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get media from database
-	medias, err := s.mediaRepo.GetByUserId(ctx, s.db, id)
+	// Get media from database, the avatars and banners belong to no tweet
+	medias, err := s.mediaRepo.GetByUserIdAndKind(ctx, s.db, id, model.MEDIA_KIND_TWEET)
 	if err != nil {
 		http.Error(w, "Failed to get media: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -72,8 +73,8 @@ func (s *Server) handleAPIMedia(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get media from database
-	medias, err := s.mediaRepo.GetByUserId(ctx, s.db, id)
+	// Get media from database, the avatars and banners belong to no tweet
+	medias, err := s.mediaRepo.GetByUserIdAndKind(ctx, s.db, id, model.MEDIA_KIND_TWEET)
 	if err != nil {
 		http.Error(w, "Failed to get media: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -153,9 +153,16 @@ func (s *Server) handleTweetsWithMedia(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	history, err := s.getProfileHistory(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to get user history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	data := map[string]interface{}{
-		"user":   user,
-		"tweets": tweetsData,
+		"user":    user,
+		"tweets":  tweetsData,
+		"history": history,
 	}
 
 	w.Header().Set("Content-Type", "text/html")
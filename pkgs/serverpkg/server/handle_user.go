@@ -1,10 +1,12 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
 
+	"github.com/WangWilly/xSync/pkgs/commonpkg/model"
 	"github.com/WangWilly/xSync/pkgs/serverpkg/serverdto"
 )
 
@@ -59,3 +61,55 @@ func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
+
+// handleAPIUserHistory serves the profile change timeline of a user as JSON
+func (s *Server) handleAPIUserHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := r.URL.Path[len("/api/user-history/"):]
+	if userID == "" {
+		http.Error(w, "User ID required", http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := s.getProfileHistory(ctx, id)
+	if err != nil {
+		http.Error(w, "Failed to get user history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// getProfileHistory builds the profile change timeline of a user, newest first
+func (s *Server) getProfileHistory(ctx context.Context, userID uint64) ([]serverdto.ProfileHistoryEntry, error) {
+	snapshots, err := s.userSnapshotRepo.ListByUid(ctx, s.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]serverdto.ProfileHistoryEntry, 0, len(snapshots))
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		var prev *model.UserSnapshot
+		if i > 0 {
+			prev = snapshots[i-1]
+		}
+
+		changes := snapshots[i].Diff(prev)
+		if len(changes) == 0 {
+			continue
+		}
+		history = append(history, serverdto.ProfileHistoryEntry{
+			Time:    snapshots[i].CreatedAt,
+			Changes: changes,
+		})
+	}
+	return history, nil
+}
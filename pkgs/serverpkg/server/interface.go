@@ -12,9 +12,13 @@ type UserRepo interface {
 }
 
 type MediaRepo interface {
-	GetByUserId(ctx context.Context, db *sqlx.DB, userId uint64) ([]*model.Media, error)
+	GetByUserIdAndKind(ctx context.Context, db *sqlx.DB, userId uint64, kind string) ([]*model.Media, error)
 }
 
 type TweetRepo interface {
 	GetWithMedia(ctx context.Context, db *sqlx.DB, userId uint64) ([]map[string]interface{}, error)
 }
+
+type UserSnapshotRepo interface {
+	ListByUid(ctx context.Context, db *sqlx.DB, uid uint64) ([]*model.UserSnapshot, error)
+}
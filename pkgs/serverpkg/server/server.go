@@ -10,6 +10,7 @@ import (
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/mediarepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/tweetrepo"
 	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/userrepo"
+	"github.com/WangWilly/xSync/pkgs/commonpkg/repos/usersnapshotrepo"
 	"github.com/WangWilly/xSync/pkgs/downloading"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
@@ -22,9 +23,10 @@ type Server struct {
 	templates *template.Template
 	port      string
 
-	userRepo  UserRepo
-	mediaRepo MediaRepo
-	tweetRepo TweetRepo
+	userRepo         UserRepo
+	mediaRepo        MediaRepo
+	tweetRepo        TweetRepo
+	userSnapshotRepo UserSnapshotRepo
 }
 
 // NewServer creates a new server instance
@@ -57,9 +59,10 @@ func NewServer(dbPath, port string) (*Server, error) {
 		templates: templates,
 		port:      port,
 
-		userRepo:  userrepo.New(),
-		mediaRepo: mediarepo.New(),
-		tweetRepo: tweetrepo.New(),
+		userRepo:         userrepo.New(),
+		mediaRepo:        mediarepo.New(),
+		tweetRepo:        tweetrepo.New(),
+		userSnapshotRepo: usersnapshotrepo.New(),
 	}, nil
 }
 
@@ -84,6 +87,7 @@ func (s *Server) setupRoutes() {
 
 	// User routes
 	http.HandleFunc("/user/", s.handleUser)
+	http.HandleFunc("/api/user-history/", s.handleAPIUserHistory)
 
 	// Tweet routes
 	http.HandleFunc("/tweets/", s.handleTweets)
@@ -49,3 +49,9 @@ type TweetsWithMediaResponse struct {
 	User   *model.User      `json:"user"`
 	Tweets []TweetWithMedia `json:"tweets"`
 }
+
+// ProfileHistoryEntry represents the profile fields that changed at one sync
+type ProfileHistoryEntry struct {
+	Time    time.Time                  `json:"time"`
+	Changes []model.UserSnapshotChange `json:"changes"`
+}